@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+)
+
+// ArchiveCmd moves completed sessions into ~/.ask/archive
+type ArchiveCmd struct {
+	Session string `help:"Session file to archive (default: session.md)"`
+	All     bool   `help:"Archive every session file in the current directory"`
+	List    bool   `help:"List archived sessions"`
+}
+
+// Run executes the archive command
+func (c *ArchiveCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	root := config.ArchivePath()
+
+	if c.List {
+		return listArchive(root)
+	}
+
+	paths := []string{c.Session}
+	if c.All {
+		matches, err := filepath.Glob("*" + filepath.Ext(config.SessionFileName()))
+		if err != nil {
+			return fmt.Errorf("failed to list sessions: %w", err)
+		}
+
+		paths = nil
+		for _, match := range matches {
+			if session.IsSession(match) {
+				paths = append(paths, match)
+			}
+		}
+
+		if len(paths) == 0 {
+			return fmt.Errorf("no session files found in current directory")
+		}
+	}
+
+	for _, path := range paths {
+		entry, err := session.Archive(path, root)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Archived %s → %s\n", path, filepath.Join(root, entry.Path))
+	}
+
+	return nil
+}
+
+// listArchive prints the archive index
+func listArchive(root string) error {
+	index, err := session.LoadArchiveIndex(root)
+	if err != nil {
+		return err
+	}
+
+	if len(index.Entries) == 0 {
+		fmt.Println("No archived sessions")
+		return nil
+	}
+
+	fmt.Printf("Archived sessions (%s):\n\n", root)
+	for _, entry := range index.Entries {
+		fmt.Printf("  %s  %s\n", entry.ArchivedAt.Format("2006-01-02"), entry.Name)
+		if entry.Title != "" {
+			fmt.Printf("              %s\n", entry.Title)
+		}
+		fmt.Printf("              from %s\n", entry.Original)
+	}
+
+	return nil
+}
+
+// RestoreCmd copies an archived session back to the current directory
+type RestoreCmd struct {
+	Name string `arg:"" help:"Archived session name (see 'ask archive --list')"`
+}
+
+// Run executes the restore command
+func (c *RestoreCmd) Run(cmdCtx *Context) error {
+	target, err := session.Restore(c.Name, config.ArchivePath(), ".")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored %s\n", target)
+	return nil
+}
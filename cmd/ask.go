@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rana/ask/internal/bedrock"
+	"github.com/rana/ask/internal/expand"
+	"github.com/rana/ask/internal/session"
+)
+
+// AskCmd asks a one-off question without a session file
+type AskCmd struct {
+	Question string   `arg:"" help:"Question to ask"`
+	Files    []string `arg:"" optional:"" help:"Files or directories to include as [[references]]"`
+	Save     string   `help:"Save the exchange as a session file at this path"`
+}
+
+// Run executes the ask command
+func (c *AskCmd) Run(cmdCtx *Context) error {
+	ctx := cmdCtx.Context
+
+	if c.Save != "" {
+		if _, err := os.Stat(c.Save); err == nil {
+			return fmt.Errorf("%s already exists", c.Save)
+		}
+	}
+
+	// Build the turn exactly as if the files were referenced in session.md
+	var b strings.Builder
+	b.WriteString(c.Question)
+	for _, file := range c.Files {
+		fmt.Fprintf(&b, "\n\n[[%s]]", file)
+	}
+
+	content, stats, err := expand.ExpandReferences(b.String(), 1, expand.Options{})
+	if err != nil {
+		return fmt.Errorf("failed to expand references: %w", err)
+	}
+
+	if len(stats) > 0 {
+		fmt.Fprintf(os.Stderr, "Expanding %d file references...\n", len(stats))
+		for _, stat := range stats {
+			fmt.Fprintf(os.Stderr, "  %s (%d tokens)\n", stat.File, stat.Tokens)
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+
+	turns := []session.Turn{
+		{Number: 1, Role: "Human", Content: content},
+	}
+
+	// Stream straight to stdout
+	var response strings.Builder
+	tokenCount, err := bedrock.StreamToClaudeWithHistory(ctx, turns, func(chunk string, currentTokens int) error {
+		response.WriteString(chunk)
+		fmt.Print(chunk)
+		return nil
+	})
+	fmt.Println()
+
+	var blocked *bedrock.GuardrailError
+	if errors.As(err, &blocked) {
+		return blocked
+	}
+	if err != nil {
+		if err != context.Canceled {
+			return fmt.Errorf("streaming failed: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Response interrupted after %d tokens\n", tokenCount)
+	}
+
+	if c.Save != "" && response.Len() > 0 {
+		turns = append(turns, session.Turn{Number: 2, Role: "AI", Content: response.String()})
+		if err := session.WriteAtomic(c.Save, []byte(renderTurns(turns))); err != nil {
+			return fmt.Errorf("failed to save %s: %w", c.Save, err)
+		}
+		fmt.Fprintf(os.Stderr, "Saved session to %s\n", c.Save)
+	}
+
+	return nil
+}
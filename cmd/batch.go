@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+	"github.com/rana/ask/internal/verbose"
+)
+
+// maxBatchParallel keeps concurrent requests below typical Bedrock throttling limits
+const maxBatchParallel = 3
+
+// BatchCmd runs chat on every session in a directory
+type BatchCmd struct {
+	Dir      string `required:"" type:"existingdir" help:"Directory of session files"`
+	Parallel int    `default:"1" help:"Sessions to process at once (at most 3)"`
+	Filter   string `enum:"incomplete,all" default:"incomplete" help:"Which sessions to process: incomplete (last turn is an unanswered human turn) or all"`
+}
+
+// batchResult is one row of the batch summary
+type batchResult struct {
+	session string
+	turn    int
+	tokens  int
+	status  string
+}
+
+// Run executes the batch command
+func (c *BatchCmd) Run(cmdCtx *Context) error {
+	if c.Parallel < 1 {
+		return fmt.Errorf("parallel must be at least 1")
+	}
+	if c.Parallel > maxBatchParallel {
+		cmdCtx.Printf("Limiting --parallel to %d to avoid throttling\n", maxBatchParallel)
+		c.Parallel = maxBatchParallel
+	}
+
+	sessions, err := c.findSessions()
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		fmt.Printf("No sessions to process in %s\n", c.Dir)
+		return nil
+	}
+
+	cmdCtx.Printf("Processing %d session(s)...\n", len(sessions))
+
+	results := make([]batchResult, len(sessions))
+	slots := make(chan struct{}, c.Parallel)
+	var wg sync.WaitGroup
+	for i, path := range sessions {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i] = runBatchSession(cmdCtx, path)
+			cmdCtx.Printf("  %s: %s\n", results[i].session, results[i].status)
+		}()
+	}
+	wg.Wait()
+
+	fmt.Println()
+	printBatchSummary(results)
+
+	failed := 0
+	for _, result := range results {
+		if result.status != "ok" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d sessions failed", failed, len(results))
+	}
+	return nil
+}
+
+// findSessions lists session files in the directory, sorted, keeping only
+// incomplete ones unless --filter=all
+func (c *BatchCmd) findSessions() ([]string, error) {
+	ext := filepath.Ext(config.SessionFileName())
+	matches, err := filepath.Glob(filepath.Join(c.Dir, "*"+ext))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", c.Dir, err)
+	}
+	sort.Strings(matches)
+
+	var sessions []string
+	for _, path := range matches {
+		if strings.HasSuffix(path, ".partial"+ext) {
+			continue
+		}
+		if c.Filter == "incomplete" && !awaitingResponse(path) {
+			continue
+		}
+		sessions = append(sessions, path)
+	}
+	return sessions, nil
+}
+
+// awaitingResponse reports whether a session ends in a human turn with content
+func awaitingResponse(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	turns, err := parseTurns(string(content))
+	if err != nil || len(turns) == 0 {
+		return false
+	}
+	last := turns[len(turns)-1]
+	return last.Role == "Human" && strings.TrimSpace(last.Content) != ""
+}
+
+// runBatchSession runs chat on one session without terminal output
+func runBatchSession(cmdCtx *Context, path string) batchResult {
+	result := batchResult{session: filepath.Base(path)}
+	if content, err := os.ReadFile(path); err == nil {
+		result.turn = session.LastTurnNumber(string(content))
+	}
+
+	chat := &ChatCmd{Session: path, batch: true}
+	quiet := &Context{Context: cmdCtx.Context, Quiet: true, Level: verbose.Silent}
+	err := chat.Run(quiet)
+
+	result.tokens = chat.tokens
+	switch {
+	case err != nil:
+		// Keep the table to one line per session
+		result.status, _, _ = strings.Cut(err.Error(), "\n")
+	case cmdCtx.Err() != nil:
+		result.status = "cancelled"
+	case chat.tokens == 0:
+		result.status = "no response"
+	default:
+		result.status = "ok"
+	}
+	return result
+}
+
+// printBatchSummary prints the session | turn | tokens | status table
+func printBatchSummary(results []batchResult) {
+	width := len("SESSION")
+	for _, result := range results {
+		width = max(width, len(result.session))
+	}
+
+	fmt.Printf("%-*s  %4s  %8s  %s\n", width, "SESSION", "TURN", "TOKENS", "STATUS")
+	for _, result := range results {
+		fmt.Printf("%-*s  %4d  %8s  %s\n", width, result.session, result.turn, formatThousands(result.tokens), result.status)
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/git"
+	"github.com/rana/ask/internal/session"
+)
+
+// branchHook runs ask branch-session after branch checkouts ($3 is 1), not file checkouts
+const branchHook = `[ "$3" = 1 ] && ask branch-session || true # ask: session per branch`
+
+// BranchSessionCmd swaps session.md for the one of the checked out branch
+type BranchSessionCmd struct{}
+
+// Run executes the branch-session command
+func (c *BranchSessionCmd) Run(cmdCtx *Context) error {
+	root, branch, path, err := branchSessionContext()
+	if err != nil {
+		return err
+	}
+	if branch == "" {
+		return nil // Detached HEAD; leave the session alone
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		// The session belongs to the branch it was last restored for
+		owner := sessionOwner(root)
+		if owner == "" {
+			owner = branch
+		}
+		if err := saveBranchSession(cmdCtx, root, owner, path); err != nil {
+			return err
+		}
+		if owner == branch {
+			return nil
+		}
+	}
+	return restoreBranchSession(cmdCtx, root, branch, path)
+}
+
+// trackBranch installs the post-checkout hook and saves or creates the
+// session of the current branch
+func (c *InitCmd) trackBranch(cmdCtx *Context) error {
+	root, branch, path, err := branchSessionContext()
+	if err != nil {
+		return err
+	}
+
+	hook, err := git.InstallHook(root, "post-checkout", branchHook)
+	if err != nil {
+		return fmt.Errorf("failed to install hook: %w", err)
+	}
+	fmt.Printf("Installed %s\n", hook)
+
+	if branch == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return saveBranchSession(cmdCtx, root, branch, path)
+	}
+	return restoreBranchSession(cmdCtx, root, branch, path)
+}
+
+// ownerFile records which branch session.md belongs to, inside .git
+const ownerFile = "ask-session-branch"
+
+// sessionOwner returns the branch session.md was saved or restored for, or "" if unknown
+func sessionOwner(root string) string {
+	path, err := git.GitPath(root, ownerFile)
+	if err != nil {
+		return ""
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// setSessionOwner records that session.md belongs to branch
+func setSessionOwner(root, branch string) error {
+	path, err := git.GitPath(root, ownerFile)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(branch+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// branchSessionContext returns the repository root, the current branch
+// ("" when HEAD is detached) and the session file at the root
+func branchSessionContext() (root, branch, path string, err error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if root, err = git.RepoRoot(wd); err != nil {
+		return "", "", "", fmt.Errorf("not in a git repository: %w", err)
+	}
+	path = filepath.Join(root, config.SessionFileName())
+
+	if branch, err = git.CurrentBranch(root); err != nil {
+		fmt.Fprintf(os.Stderr, "Keeping %s: %v\n", path, err)
+		return root, "", path, nil
+	}
+	return root, branch, path, nil
+}
+
+// branchSessionPath returns where the session of branch in the repository at root is kept
+func branchSessionPath(root, branch, ext string) string {
+	return filepath.Join(config.BranchSessionsPath(), session.Slug(filepath.Base(root)), session.Slug(branch)+ext)
+}
+
+// saveBranchSession copies the session at path to branch's saved session
+func saveBranchSession(cmdCtx *Context, root, branch, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	saved := branchSessionPath(root, branch, filepath.Ext(path))
+	if err := os.MkdirAll(filepath.Dir(saved), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(saved), err)
+	}
+	if err := session.WriteAtomic(saved, content); err != nil {
+		return fmt.Errorf("failed to save session for %s: %w", branch, err)
+	}
+	cmdCtx.Printf("Saved session for %s to %s\n", branch, saved)
+	return setSessionOwner(root, branch)
+}
+
+// restoreBranchSession replaces the session at path with branch's saved
+// session, or a new one if the branch has none. It copies rather than
+// symlinks because session writes replace the file, which would break a link.
+func restoreBranchSession(cmdCtx *Context, root, branch, path string) error {
+	saved := branchSessionPath(root, branch, filepath.Ext(path))
+	content, err := os.ReadFile(saved)
+	if err == nil {
+		if err := session.WriteAtomic(path, content); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+		cmdCtx.Printf("Restored session for %s from %s\n", branch, saved)
+		return setSessionOwner(root, branch)
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", saved, err)
+	}
+
+	// Hooks have no terminal to answer template prompts
+	initial, err := (&InitCmd{}).initialContent(nil)
+	if err != nil {
+		return err
+	}
+	if err := session.WriteAtomic(path, []byte(initial)); err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	cmdCtx.Printf("Started a new session for %s\n", branch)
+	return setSessionOwner(root, branch)
+}
@@ -2,21 +2,54 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rana/ask/internal/bedrock"
 	"github.com/rana/ask/internal/config"
 	"github.com/rana/ask/internal/expand"
 	"github.com/rana/ask/internal/session"
+	"github.com/rana/ask/internal/verbose"
 )
 
 // ChatCmd processes the chat session
-type ChatCmd struct{}
+type ChatCmd struct {
+	Session       string   `help:"Session file path (default: session.md)"`
+	FreshOnly     bool     `help:"Fail if a referenced file was modified after session.md"`
+	FailFast      bool     `help:"Fail on the first expansion error instead of skipping files"`
+	From          int      `help:"Regenerate from human turn N, discarding later turns"`
+	Confirm       bool     `help:"Confirm discarding turns when using --from"`
+	Include       []string `help:"Extra file pattern to include for this run (repeatable)"`
+	Exclude       []string `help:"Extra file pattern to exclude for this run (repeatable)"`
+	Tee           bool     `help:"Also write the raw response to stdout, without status output"`
+	Preflight     bool     `help:"Check credentials and model access with a one-token request, then exit"`
+	Budget        int      `help:"Send only the most recent turns that fit in N estimated tokens"`
+	ThinkingOut   string   `type:"path" help:"Write extended thinking to this file, replacing it each run"`
+	InitIfMissing bool     `help:"Create the session if it doesn't exist, using piped stdin as the first question"`
+	Incremental   bool     `help:"Only expand references that are new or whose files changed since they were last expanded"`
+	Fifo          string   `type:"path" help:"Create a named pipe at this path and stream the response to it"`
+	Force         bool     `name:"force-overwrite" help:"Write the session even if another process modified it during this run"`
+
+	batch  bool // Run by ask batch, which leaves stdout to its summary
+	tokens int  // Tokens in the response, for ask batch
+}
 
 // Run executes the chat command
 func (c *ChatCmd) Run(cmdCtx *Context) error {
+	path := cmdCtx.SessionPath(c.Session)
+
+	// Stdout carries only the response, so status output would corrupt it
+	if c.Tee {
+		cmdCtx.Quiet = true
+		cmdCtx.Level = verbose.Silent
+	}
+
 	// Use the context from main that has signal handling
 	ctx := cmdCtx.Context
 
@@ -24,20 +57,75 @@ func (c *ChatCmd) Run(cmdCtx *Context) error {
 	cfg, err := config.Load()
 	if err != nil {
 		// Continue with defaults if config fails
-		fmt.Printf("Warning: using default configuration: %v\n", err)
+		cmdCtx.Printf("Warning: using default configuration: %v\n", err)
 	}
 
+	if c.Preflight {
+		return preflight(cmdCtx, path)
+	}
+
+	// Refresh profiles nearing expiry while this command runs. It is
+	// cancelled and waited for on return, so the cache is never left half
+	// written when the process exits.
+	refreshCtx, cancelRefresh := context.WithCancel(ctx)
+	refreshDone := make(chan struct{})
+	go func() {
+		defer close(refreshDone)
+		bedrock.RefreshProfiles(refreshCtx, cfg != nil && cfg.Uses1MContext())
+	}()
+	defer func() {
+		cancelRefresh()
+		<-refreshDone
+	}()
+
 	// Check if session.md exists
-	content, err := os.ReadFile("session.md")
+	sessionInfo, err := os.Stat(path)
+	if os.IsNotExist(err) && (c.InitIfMissing || (cfg != nil && cfg.Session.AutoInit)) {
+		if err := initMissingSession(cmdCtx, path); err != nil {
+			return err
+		}
+		sessionInfo, err = os.Stat(path)
+	}
 	if err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("no session.md found. Run 'ask init' to start")
+			return fmt.Errorf("no %s found. Run 'ask init' to start", path)
 		}
-		return fmt.Errorf("failed to read session.md: %w", err)
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	// Checked before expansion, which only makes the session larger
+	if cfg != nil && cfg.Session.MaxSizeBytes > 0 && sessionInfo.Size() > cfg.Session.MaxSizeBytes {
+		return fmt.Errorf("%s (%s) exceeds max size limit of %s. Run 'ask archive' and start a new session, or raise it with 'ask cfg session-max-size'",
+			path, formatSize(sessionInfo.Size()), formatSize(cfg.Session.MaxSizeBytes))
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if c.From != 0 {
+		if content, err = c.truncateFrom(cmdCtx, path, string(content)); err != nil {
+			return err
+		}
+	}
+
+	// Another process, e.g. an editor or ask watch, may save the session
+	// while references expand; its changes must not be overwritten
+	readHash := session.ContentHash(content)
+	if c.Force {
+		readHash = ""
+	}
+
+	// A session-level model annotation overrides the configured model
+	meta := session.ReadMetadata(string(content))
+	if meta.Model != "" && cfg != nil {
+		cfg.Model = meta.Model
 	}
 
 	// Parse all turns from the session
-	turns, err := session.ParseAllTurns(string(content))
+	format := sessionFormat()
+	turns, err := format.Parse(string(content))
 	if err != nil {
 		return fmt.Errorf("failed to parse session: %w", err)
 	}
@@ -52,7 +140,15 @@ func (c *ChatCmd) Run(cmdCtx *Context) error {
 	}
 
 	if lastHumanIndex == -1 {
-		return fmt.Errorf("no human turn found in session.md")
+		return fmt.Errorf("no human turn found in %s", path)
+	}
+
+	// The final turn in the file must be active, or the response would
+	// be appended after a turn that was never sent
+	lastTurnNumber := session.LastTurnNumber(string(content))
+	if session.IsNative(format) && turns[len(turns)-1].Number != lastTurnNumber {
+		return fmt.Errorf("turn %d is excluded by its condition. Set the variable or remove the annotation",
+			lastTurnNumber)
 	}
 
 	// Check if the last human turn has content
@@ -62,75 +158,288 @@ func (c *ChatCmd) Run(cmdCtx *Context) error {
 	}
 
 	// Expand file references in all human turns
+	expandStart := time.Now()
 	totalExpansions := 0
 	var allStats []expand.FileStat
 	originalContent := string(content)
 	updatedContent := originalContent
+	expandOpts := expand.Options{
+		SessionModTime: sessionInfo.ModTime(),
+		FreshOnly:      c.FreshOnly,
+		FailFast:       c.FailFast,
+		Quiet:          cmdCtx.Level < verbose.Normal,
+	}
+
+	// One-off patterns apply to a copy so the config is left untouched
+	if len(c.Include) > 0 || len(c.Exclude) > 0 {
+		expandCfg, err := config.LoadExpandConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load expansion rules: %w", err)
+		}
+		overridden := expandCfg.WithOverrides(c.Include, c.Exclude)
+		expandOpts.Expand = &overridden
+	}
+
+	// Continue numbering after sections expanded in earlier runs
+	globalSections := cfg != nil && cfg.Expand.GlobalSectionNumbers
+	if globalSections {
+		expandOpts.FirstSection = expand.LastSectionNumber(originalContent) + 1
+	}
+
+	// Stdin can only be read once, so it can only be inlined once
+	stdinRefs := 0
+	for _, turn := range turns {
+		if turn.Role == "Human" {
+			stdinRefs += expand.CountStdinReferences(turn.Content)
+		}
+	}
+	if stdinRefs > 1 {
+		return fmt.Errorf("only one [[stdin]] reference is allowed per session, found %d", stdinRefs)
+	}
+
+	// With --incremental, references whose files haven't changed since they
+	// were recorded stay unexpanded, and the rest are recorded afresh
+	type expansionRecord struct {
+		turn    int
+		ref     string
+		modTime time.Time
+	}
+	var records []expansionRecord
+	skipped := 0
 
 	for i, turn := range turns {
 		if turn.Role == "Human" {
-			expanded, stats, err := expand.ExpandReferences(turn.Content, turn.Number)
+			// Only the last human turn's expansion is written back, so only
+			// its references are recorded and may be skipped next time
+			var unchanged map[string]bool
+			if c.Incremental && i == lastHumanIndex {
+				unchanged = unchangedReferences(turn.Content, session.GetExpansionRecord(path, turn.Number))
+				skipped += len(unchanged)
+				expandOpts.Skip = func(ref string) bool { return unchanged[ref] }
+			}
+
+			expanded, stats, err := expand.ExpandReferences(turn.Content, turn.Number, expandOpts)
 			if err != nil {
 				return fmt.Errorf("failed to expand references in turn %d: %w", turn.Number, err)
 			}
 
-			if len(stats) > 0 {
-				// Update the turn with expanded content
+			if c.Incremental && i == lastHumanIndex {
+				for _, match := range expand.ReferencePattern.FindAllStringSubmatch(turn.Content, -1) {
+					ref := match[1]
+					if unchanged[ref] || expand.IsFootnote(ref) {
+						continue
+					}
+					if modTime, err := expand.ReferenceModTime(ref); err == nil {
+						records = append(records, expansionRecord{turn.Number, ref, modTime})
+					}
+				}
+			}
+			if globalSections {
+				expandOpts.FirstSection += len(stats)
+			}
+
+			// Footnotes may change the turn without expanding any file
+			if expanded != turn.Content {
 				turns[i].Content = expanded
+			}
+
+			if len(stats) > 0 {
 				allStats = append(allStats, stats...)
 				totalExpansions += len(stats)
 
-				// Update session.md with expanded content if this is the last human turn
+				// Update session.md with expanded content if this is the last human turn,
+				// recording what was added alongside any earlier expansion of it
 				if i == lastHumanIndex {
-					updatedContent = session.ReplaceLastHumanTurn(originalContent, turn.Number, expanded)
+					meta := session.ParseExpansionMetadata(originalContent)[turn.Number]
+					meta.Files += len(stats)
+					for _, stat := range stats {
+						meta.Tokens += stat.Tokens
+					}
+					meta.At = time.Now()
+					if session.IsNative(format) {
+						updatedContent = session.ReplaceLastHumanTurn(originalContent, turn.Number,
+							expanded+"\n\n"+meta.Comment())
+					} else {
+						updatedContent = session.ReplaceTurnContent(originalContent, turn, expanded+"\n\n"+meta.Comment())
+					}
 				}
 			}
 		}
 	}
 
+	for _, r := range records {
+		updatedContent = session.RecordExpansion(updatedContent, r.turn, r.ref, r.modTime)
+	}
+	if skipped > 0 {
+		cmdCtx.Printf("Skipping %d unchanged references\n", skipped)
+	}
+
 	// Show expansion stats (only if there are expansions)
 	if totalExpansions > 0 {
-		fmt.Printf("Expanding %d file references...\n", totalExpansions)
+		cmdCtx.Printf("Expanding %d file references...\n", totalExpansions)
 		for _, stat := range allStats {
 			// Show directory indicator for multiple files from same dir
 			if strings.Contains(stat.File, "/") {
-				fmt.Printf("  %s (%d tokens)\n", stat.File, stat.Tokens)
+				cmdCtx.Printf("  %s (%d tokens)\n", stat.File, stat.Tokens)
 			} else {
-				fmt.Printf("  %s (%d tokens)\n", stat.File, stat.Tokens)
+				cmdCtx.Printf("  %s (%d tokens)\n", stat.File, stat.Tokens)
+			}
+		}
+		cmdCtx.Println()
+	}
+
+	// Drop the oldest unpinned turns that don't fit the budget
+	if c.Budget > 0 {
+		total := len(turns)
+		var dropped int
+		turns, dropped = session.TrimToTokenBudget(turns, c.Budget)
+		if dropped > 0 {
+			cmdCtx.Printf("Sending %d/%d turns (%s tokens, dropped %d turns to fit budget)\n\n",
+				len(turns), total, formatThousands(session.EstimateTokens(turns)), dropped)
+		}
+	}
+
+	// Recall passages of earlier sessions into the question being sent
+	if cfg != nil && cfg.Memory.Enabled {
+		if err := recallMemory(cmdCtx, cfg, path, turns); err != nil {
+			return fmt.Errorf("failed to recall memory: %w", err)
+		}
+	}
+
+	// Injected files are sent ahead of the conversation but never written to it
+	injected, err := injectedContext(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to inject context: %w", err)
+	}
+	turns = session.InjectContext(turns, injected)
+
+	// Pick the model from the prompt size unless the session names one
+	if cfg != nil && cfg.ModelAutoSelect.Enabled && meta.Model == "" {
+		tokens := session.EstimateTokens(turns)
+		if t, next, ok := cfg.ModelAutoSelect.Select(tokens); ok {
+			meta.Model = t.Model
+			cfg.Model = t.Model
+			if next > 0 {
+				cmdCtx.Printf("Auto-selected model: %s (%s tokens < %s threshold)\n",
+					t.Model, formatThousands(tokens), formatThousands(next))
+			} else {
+				cmdCtx.Printf("Auto-selected model: %s (%s tokens >= %s threshold)\n",
+					t.Model, formatThousands(tokens), formatThousands(t.MinTokens))
 			}
 		}
-		fmt.Println()
 	}
 
 	// Show model being used
+	var modelID string
 	if cfg != nil {
-		modelID, _ := cfg.ResolveModel()
-		fmt.Printf("Model: %s\n", modelID)
+		modelID, _ = cfg.ResolveModel()
+		cmdCtx.Printf("Model: %s\n", modelID)
+		if modelID == "" {
+			modelID = cfg.Model // For hooks
+		}
 		if cfg.Thinking.Enabled {
-			fmt.Printf("Thinking: enabled (budget: %d tokens)\n", cfg.GetThinkingTokens())
+			cmdCtx.Printf("Thinking: enabled (budget: %d tokens)\n", cfg.GetThinkingTokens())
 		}
 	}
-	fmt.Println()
+	cmdCtx.Println()
 
 	// Write expanded content if we had expansions
-	if totalExpansions > 0 {
-		if err := session.WriteAtomic("session.md", []byte(updatedContent)); err != nil {
-			return fmt.Errorf("failed to update session.md: %w", err)
-		}
+	if updatedContent != originalContent {
+		err = session.WriteIfUnchanged(path, []byte(updatedContent), readHash)
+	} else {
+		err = session.CheckUnchanged(path, readHash)
+	}
+	if errors.Is(err, session.ErrModified) {
+		return fmt.Errorf("%s was modified by another process. Retry or use --force-overwrite", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
 	}
 
 	// Calculate next turn number
 	nextTurnNumber := turns[len(turns)-1].Number + 1
 
+	// A pre-chat hook can veto the request, e.g. when the code doesn't build
+	if cfg != nil && cfg.Hooks.PreChat != "" {
+		cmdCtx.Logf(verbose.Verbose, "Running pre-chat hook: %s\n", cfg.Hooks.PreChat)
+		output, err := runHook(cfg.Hooks.PreChat, hookEnv{path, nextTurnNumber - 1, modelID})
+		if err != nil {
+			return fmt.Errorf("pre-chat hook failed (%v):\n%s", err, output)
+		}
+		if output != "" {
+			cmdCtx.Logf(verbose.Verbose, "%s\n", output)
+		}
+	}
+
 	// Stream the response
-	fmt.Println("Streaming response... [ctrl+c to interrupt]")
+	expandTime := time.Since(expandStart)
+	streamStart := time.Now()
+	var firstChunk time.Duration
+	cmdCtx.Println("Streaming response... [ctrl+c to interrupt]")
+
+	// Thinking goes to its own file so it doesn't end up in the session
+	var onThinking bedrock.ThinkingCallback
+	var thinkingFile *os.File
+	if c.ThinkingOut != "" {
+		thinkingFile, err = os.Create(c.ThinkingOut)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", c.ThinkingOut, err)
+		}
+		defer thinkingFile.Close()
+
+		if cfg != nil && !cfg.Thinking.Enabled {
+			cmdCtx.Printf("Note: thinking is disabled, so %s will stay empty. Enable with: ask cfg thinking on\n", c.ThinkingOut)
+		}
+		onThinking = func(chunk string) error {
+			_, err := thinkingFile.WriteString(chunk)
+			return err
+		}
+	}
+
+	// A reader such as 'cat' in another pane follows the response live
+	var fifo *fifoWriter
+	if c.Fifo != "" {
+		if fifo, err = createFIFO(c.Fifo); err != nil {
+			return err
+		}
+		defer fifo.Close()
+		cmdCtx.Printf("Streaming to %s\n", c.Fifo)
+	}
 
 	var finalTokenCount int
-	err = session.StreamResponse("session.md", nextTurnNumber, func(writer *session.StreamWriter) (int, error) {
+	err = session.StreamResponse(path, nextTurnNumber, func(writer *session.StreamWriter) (int, error) {
+		if cfg != nil && cfg.Session.Timing {
+			writer.EnableTiming()
+		}
+		writer.SetFormat(format)
+		if cfg != nil {
+			writer.SetFenceDepth(cfg.Session.FenceDepth)
+			writer.SetBuffering(cfg.Streaming.MaxBufferKB*1024, cfg.Streaming.Interval())
+		}
+
+		if cfg != nil && cfg.Session.UsePartialFile {
+			if err := writer.EnablePartialFile(session.PartialPath(path)); err != nil {
+				return 0, err
+			}
+		}
+
+		if fifo != nil {
+			writer.Tee(fifo)
+		}
+
+		// In quiet mode the response is the only output
+		if cmdCtx.Quiet && !c.batch {
+			writer.Tee(os.Stdout)
+		}
+
 		// Progress indicator in terminal
 		lastPrintedTokens := 0
 
-		tokenCount, err := bedrock.StreamToClaudeWithHistory(ctx, turns, func(chunk string, currentTokens int) error {
+		tokenCount, err := bedrock.StreamToClaudeWithThinking(ctx, meta.Model, turns, func(chunk string, currentTokens int) error {
+			if firstChunk == 0 {
+				firstChunk = time.Since(streamStart)
+			}
+
 			// Write chunk to file
 			if err := writer.WriteChunk(chunk); err != nil {
 				return err
@@ -138,37 +447,206 @@ func (c *ChatCmd) Run(cmdCtx *Context) error {
 
 			// Update terminal progress (print every 100 tokens)
 			if currentTokens-lastPrintedTokens >= 100 || currentTokens < 100 {
-				fmt.Printf("\rStreaming response... %d tokens [ctrl+c to interrupt]", currentTokens)
+				cmdCtx.Printf("\rStreaming response... %d tokens [ctrl+c to interrupt]", currentTokens)
 				lastPrintedTokens = currentTokens
 			}
 
 			return nil
+		}, onThinking, func() error {
+			// The connection dropped, so start the response over
+			if thinkingFile != nil {
+				if err := thinkingFile.Truncate(0); err != nil {
+					return err
+				}
+				if _, err := thinkingFile.Seek(0, io.SeekStart); err != nil {
+					return err
+				}
+			}
+			lastPrintedTokens = 0
+			return writer.Rewind()
 		})
 
 		finalTokenCount = tokenCount
 		return tokenCount, err
 	})
 
+	c.tokens = finalTokenCount
+	if cmdCtx.Quiet && !c.batch && finalTokenCount > 0 {
+		fmt.Println()
+	}
+
 	// Clear the streaming line
-	fmt.Print("\r                                                           \r")
+	cmdCtx.Printf("\r                                                           \r")
 
+	var blocked *bedrock.GuardrailError
+	if errors.As(err, &blocked) {
+		return blocked
+	}
 	if err != nil {
 		if err == context.Canceled {
 			if finalTokenCount > 0 {
-				fmt.Printf("Response interrupted after %d tokens\n", finalTokenCount)
+				cmdCtx.Logf(verbose.Minimal, "Response interrupted after %d tokens\n", finalTokenCount)
 			} else {
-				fmt.Printf("Cancelled before response started\n")
+				cmdCtx.Printf("Cancelled before response started\n")
 			}
 		} else {
 			return fmt.Errorf("streaming failed: %w", err)
 		}
 	} else {
 		if finalTokenCount > 0 {
-			fmt.Printf("Response complete: %d tokens\n", finalTokenCount)
+			cmdCtx.Logf(verbose.Minimal, "Response complete: %d tokens\n", finalTokenCount)
 		} else {
-			fmt.Printf("No response received\n")
+			cmdCtx.Printf("No response received\n")
+		}
+	}
+
+	cmdCtx.Logf(verbose.Verbose, "Timings: expansion %s, first token %s, total streaming %s\n",
+		expandTime.Round(time.Millisecond), firstChunk.Round(time.Millisecond),
+		time.Since(streamStart).Round(time.Millisecond))
+
+	// The response is already saved, so a failing post-chat hook only warns
+	if cfg != nil && cfg.Hooks.PostChat != "" && err == nil && finalTokenCount > 0 {
+		cmdCtx.Logf(verbose.Verbose, "Running post-chat hook: %s\n", cfg.Hooks.PostChat)
+		output, err := runHook(cfg.Hooks.PostChat, hookEnv{path, nextTurnNumber, modelID})
+		if err != nil {
+			cmdCtx.Printf("Warning: post-chat hook failed (%v):\n%s\n", err, output)
+		} else if output != "" {
+			cmdCtx.Logf(verbose.Verbose, "%s\n", output)
+		}
+	}
+
+	// Keep ask search current, including after an interrupted response
+	if finalTokenCount > 0 {
+		model := ""
+		if cfg != nil {
+			model = cfg.Model
 		}
+		if err := updateSessionIndex(path, model); err != nil {
+			cmdCtx.Logf(verbose.Verbose, "Warning: failed to update session index: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// formatThousands formats n with comma separators, e.g. 18,432
+func formatThousands(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// initMissingSession creates a session whose first turn is read from piped
+// stdin. Without piped input there is nothing to send yet, so it stops.
+func initMissingSession(cmdCtx *Context, path string) error {
+	var question string
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		question = strings.TrimSpace(string(input))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	content := sessionFormat().Header(1, "Human") + "\n\n"
+	if question != "" {
+		content += question + "\n"
+	}
+	if err := session.WriteAtomic(path, []byte(content)); err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	if question == "" {
+		return fmt.Errorf("%s created — add your question before running again", path)
+	}
+
+	cmdCtx.Printf("Created %s\n", path)
+	return nil
+}
+
+// preflight verifies the model the session would use is reachable
+func preflight(cmdCtx *Context, path string) error {
+	var model string
+	if content, err := os.ReadFile(path); err == nil {
+		model = session.ReadMetadata(string(content)).Model
 	}
 
+	modelID, profileArn, err := bedrock.Preflight(cmdCtx.Context, model)
+	if err != nil {
+		return fmt.Errorf("preflight failed: %w", err)
+	}
+
+	fmt.Printf("Model OK: %s\n", modelID)
+	fmt.Printf("Profile: %s\n", profileArn)
 	return nil
 }
+
+// truncateFrom drops turns after c.From once confirmed, returning the new content
+func (c *ChatCmd) truncateFrom(cmdCtx *Context, path, content string) ([]byte, error) {
+	// Truncation finds turns by their ask-md headers
+	if !session.IsNative(sessionFormat()) {
+		return nil, fmt.Errorf("--from needs %s sessions", session.DefaultFormat)
+	}
+
+	turns, err := session.ParseAllTurns(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	var from *session.Turn
+	for i := range turns {
+		if turns[i].Number == c.From {
+			from = &turns[i]
+			break
+		}
+	}
+	if from == nil {
+		return nil, fmt.Errorf("turn %d not found in %s", c.From, path)
+	}
+	if from.Role != "Human" {
+		return nil, fmt.Errorf("turn %d is an AI turn. Use the human turn before it", c.From)
+	}
+
+	lastTurnNumber := session.LastTurnNumber(content)
+	if lastTurnNumber == c.From {
+		return []byte(content), nil
+	}
+
+	cmdCtx.Printf("Re-generating from turn %d. Turns %d–%d will be overwritten.\n", c.From, c.From+1, lastTurnNumber)
+	if !c.Confirm {
+		return nil, fmt.Errorf("turns %d–%d would be overwritten. Run with --confirm to continue", c.From+1, lastTurnNumber)
+	}
+
+	if err := session.TruncateAfterTurn(path, c.From); err != nil {
+		return nil, fmt.Errorf("failed to truncate %s: %w", path, err)
+	}
+	cmdCtx.Printf("Previous session saved to %s.bak\n", path)
+
+	truncated, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return truncated, nil
+}
+
+// unchangedReferences returns the references in content that are recorded
+// and whose files haven't been modified since
+func unchangedReferences(content string, record map[string]time.Time) map[string]bool {
+	unchanged := make(map[string]bool)
+	for _, match := range expand.ReferencePattern.FindAllStringSubmatch(content, -1) {
+		ref := match[1]
+		recorded, ok := record[ref]
+		if !ok {
+			continue
+		}
+		if modTime, err := expand.ReferenceModTime(ref); err == nil && !modTime.After(recorded) {
+			unchanged[ref] = true
+		}
+	}
+	return unchanged
+}
@@ -2,8 +2,53 @@ package cmd
 
 // CLI represents the command-line interface
 type CLI struct {
-	Init    InitCmd    `cmd:"" help:"Initialize a new session"`
-	Chat    ChatCmd    `cmd:"" default:"1" help:"Process the session (default)"`
-	Cfg     CfgCmd     `cmd:"" help:"Manage configuration"`
-	Version VersionCmd `cmd:"" help:"Show version information"`
+	Quiet   bool   `help:"Print only the response, with no status output" env:"ASK_QUIET"`
+	Global  bool   `help:"Use the global session in ~/.ask/global instead of ./session.md"`
+	Config  string `type:"path" help:"Config file to use instead of ~/.ask/cfg.toml" env:"ASK_CONFIG"`
+	Verbose bool   `help:"Same as --verbosity=3" env:"ASK_VERBOSE"`
+
+	Verbosity int `default:"2" help:"Status output: 0 silent, 1 final token count, 2 normal, 3 verbose" env:"ASK_VERBOSITY"`
+
+	Init       InitCmd       `cmd:"" help:"Initialize a new session"`
+	Chat       ChatCmd       `cmd:"" default:"1" help:"Process the session (default)"`
+	Ask        AskCmd        `cmd:"" help:"Ask a one-off question without a session file"`
+	Paste      PasteCmd      `cmd:"" help:"Start a session from the clipboard and run it"`
+	Resume     ResumeCmd     `cmd:"" help:"Recover an unfinished response from session.partial.md"`
+	Next       NextCmd       `cmd:"" help:"Append the next human turn heading"`
+	Watch      WatchCmd      `cmd:"" help:"Run chat whenever the session is saved with a new question"`
+	Batch      BatchCmd      `cmd:"" help:"Run chat on each incomplete session in a directory"`
+	ExecLast   ExecLastCmd   `cmd:"" help:"Run shell blocks from the last AI turn"`
+	Extract    ExtractCmd    `cmd:"" help:"Write code blocks from an AI turn to files"`
+	Fmt        FmtCmd        `cmd:"" help:"Normalize formatting of an AI turn"`
+	Renumber   RenumberCmd   `cmd:"" help:"Number turns sequentially in file order after manual edits"`
+	Hash       HashCmd       `cmd:"" help:"Print SHA256 hashes of session turns"`
+	Grep       GrepCmd       `cmd:"" help:"Search the session, showing the turn of each match"`
+	Search     SearchCmd     `cmd:"" help:"Find sessions by path, tags, models or first question"`
+	Index      IndexCmd      `cmd:"" help:"Manage the session index ask search queries"`
+	Preview    PreviewCmd    `cmd:"" help:"Show a human turn as it would be sent"`
+	Count      CountCmd      `cmd:"" help:"Count input tokens the session would send"`
+	Stats      StatsCmd      `cmd:"" help:"Show files and tokens added by expansion per turn"`
+	Meta       MetaCmd       `cmd:"" help:"Print session statistics as JSON"`
+	ModelInfo  ModelInfoCmd  `cmd:"" name:"model-info" help:"Show the capabilities of the resolved model"`
+	DiffModels DiffModelsCmd `cmd:"" name:"diff-models" help:"Send the last human turn to two models and diff the responses"`
+	Usage      UsageCmd      `cmd:"" help:"Show token usage and estimated cost per model"`
+	Quota      QuotaCmd      `cmd:"" help:"Show the Bedrock quotas for the configured model"`
+	Memory     MemoryCmd     `cmd:"" help:"Manage the index ask chat recalls earlier sessions from"`
+	Tag        TagCmd        `cmd:"" aliases:"tags" help:"Tag sessions and turns"`
+	Title      TitleCmd      `cmd:"" help:"Infer a short title from the first human turn"`
+	Files      FilesCmd      `cmd:"" help:"List file references and whether they resolve"`
+	FilterTest FilterTestCmd `cmd:"" name:"filter-test" help:"Show which lines of a file filtering would remove"`
+	Import     ImportCmd     `cmd:"" help:"Create a session from an exported conversation"`
+	Export     ExportCmd     `cmd:"" help:"Write the session out in another format"`
+	Archive    ArchiveCmd    `cmd:"" help:"Move completed sessions to ~/.ask/archive"`
+	Restore    RestoreCmd    `cmd:"" help:"Restore an archived session"`
+	SetModel   SetModelCmd   `cmd:"" help:"Set the model for this session only"`
+	Inject     InjectCmd     `cmd:"" help:"Send a file as context with every request in this session"`
+	Cfg        CfgCmd        `cmd:"" help:"Manage configuration"`
+	Doctor     DoctorCmd     `cmd:"" help:"Check the config, AWS access and session for problems"`
+	Warmup     WarmupCmd     `cmd:"" help:"Cache inference profiles and test-invoke each model"`
+	Version    VersionCmd    `cmd:"" help:"Show version information"`
+
+	// Run by the post-checkout hook that ask init --track-branch installs
+	BranchSession BranchSessionCmd `cmd:"" name:"branch-session" help:"Swap in the session of the checked out git branch"`
 }
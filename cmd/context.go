@@ -1,8 +1,77 @@
 package cmd
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+	"github.com/rana/ask/internal/verbose"
+)
 
 // Context wraps context for command execution
 type Context struct {
 	context.Context
+	Quiet         bool          // Stdout carries only the response
+	GlobalSession bool          // Use ~/.ask/global/session.md instead of the working directory
+	Level         verbose.Level // How much status output to print
+}
+
+// SessionPath returns the session file to use: the global session when
+// --global is set, otherwise path, defaulting to session.<ext> in the
+// configured session directory
+func (c *Context) SessionPath(path string) string {
+	switch {
+	case c.GlobalSession:
+		path = config.GlobalSessionPath()
+	case path == "":
+		path = filepath.Join(config.SessionDir(), config.SessionFileName())
+	}
+	if c.Level >= verbose.Verbose {
+		fmt.Fprintf(os.Stderr, "Session: %s\n", path)
+	}
+	return path
+}
+
+// Printf prints status output at normal verbosity and above
+func (c *Context) Printf(format string, a ...interface{}) {
+	c.Logf(verbose.Normal, format, a...)
+}
+
+// Println prints status output at normal verbosity and above
+func (c *Context) Println(a ...interface{}) {
+	if c.Level >= verbose.Normal {
+		fmt.Println(a...)
+	}
+}
+
+// Logf prints status output when the verbosity is at least level
+func (c *Context) Logf(level verbose.Level, format string, a ...interface{}) {
+	if c.Level >= level {
+		fmt.Printf(format, a...)
+	}
+}
+
+// sessionFormat returns the configured session format, falling back to
+// ask-md when the config can't be read
+func sessionFormat() session.Format {
+	if cfg, err := config.Load(); err == nil {
+		if f, err := session.LookupFormat(cfg.Session.Format); err == nil {
+			return f
+		}
+	}
+	f, _ := session.LookupFormat(session.DefaultFormat)
+	return f
+}
+
+// parseTurns parses a session in the configured session format
+func parseTurns(content string) ([]session.Turn, error) {
+	return sessionFormat().Parse(content)
+}
+
+// renderTurns renders turns in the configured session format
+func renderTurns(turns []session.Turn) string {
+	return sessionFormat().Render(turns)
 }
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rana/ask/internal/bedrock"
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/expand"
+	"github.com/rana/ask/internal/session"
+)
+
+// CountCmd reports how many input tokens the session would send
+type CountCmd struct {
+	Session string `help:"Session file path (default: session.md)"`
+	API     bool   `name:"api" help:"Ask Bedrock for the exact count instead of estimating"`
+}
+
+// Run executes the count command
+func (c *CountCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	content, err := os.ReadFile(c.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", c.Session)
+		}
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+
+	turns, err := parseTurns(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	// Count what chat would send, with references expanded
+	for i, turn := range turns {
+		if turn.Role == "Human" {
+			expanded, _, err := expand.ExpandReferences(turn.Content, turn.Number, expand.Options{Quiet: true})
+			if err != nil {
+				return fmt.Errorf("failed to expand references in turn %d: %w", turn.Number, err)
+			}
+			turns[i].Content = expanded
+		}
+	}
+
+	injected, err := injectedContext(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to inject context: %w", err)
+	}
+	turns = session.InjectContext(turns, injected)
+
+	estimate := 0
+	for _, turn := range turns {
+		estimate += len(turn.Content) / 4 // Rough approximation
+	}
+
+	if !c.API {
+		fmt.Printf("~%d tokens (estimate, %d turns)\n", estimate, len(turns))
+		return nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if meta := session.ReadMetadata(string(content)); meta.Model != "" {
+		cfg.Model = meta.Model
+	}
+	modelID, err := cfg.ResolveModel()
+	if err != nil {
+		return fmt.Errorf("failed to resolve model: %w", err)
+	}
+
+	tokens, err := bedrock.CountTokensForTurns(cmdCtx.Context, turns, modelID)
+	if err != nil {
+		cmdCtx.Printf("Warning: %v, falling back to estimate\n", err)
+		fmt.Printf("~%d tokens (estimate, %d turns)\n", estimate, len(turns))
+		return nil
+	}
+
+	fmt.Printf("%d tokens (%s, %d turns)\n", tokens, modelID, len(turns))
+	return nil
+}
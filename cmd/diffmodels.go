@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/rana/ask/internal/analytics"
+	"github.com/rana/ask/internal/bedrock"
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/expand"
+	"github.com/rana/ask/internal/session"
+	"github.com/rana/ask/internal/textdiff"
+)
+
+// DiffModelsCmd sends the session to two models and diffs their responses
+type DiffModelsCmd struct {
+	Session string `help:"Session file path (default: session.md)"`
+	A       string `name:"a" required:"" help:"First model (e.g. haiku)"`
+	B       string `name:"b" required:"" help:"Second model (e.g. sonnet)"`
+}
+
+// Run executes the diff-models command
+func (c *DiffModelsCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	// A provisioned model answers for every model name, so both would be the same
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.ProvisionedARN != "" {
+		return fmt.Errorf("provisioned_arn sends both models to %s. Clear it with: ask cfg provisioned-arn off", cfg.ProvisionedARN)
+	}
+
+	content, err := os.ReadFile(c.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", c.Session)
+		}
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+
+	turns, err := parseTurns(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	// Send the conversation up to the last question, as chat would
+	lastHuman := -1
+	for i := len(turns) - 1; i >= 0; i-- {
+		if turns[i].Role == "Human" {
+			lastHuman = i
+			break
+		}
+	}
+	if lastHuman == -1 {
+		return fmt.Errorf("no human turn found in %s", c.Session)
+	}
+	if turns[lastHuman].Content == "" {
+		return fmt.Errorf("turn %d has no content. Add your thoughts and try again", turns[lastHuman].Number)
+	}
+	turns = turns[:lastHuman+1]
+
+	for i, turn := range turns {
+		if turn.Role == "Human" {
+			expanded, _, err := expand.ExpandReferences(turn.Content, turn.Number, expand.Options{Quiet: true})
+			if err != nil {
+				return fmt.Errorf("failed to expand references in turn %d: %w", turn.Number, err)
+			}
+			turns[i].Content = expanded
+		}
+	}
+
+	number := turns[lastHuman].Number
+	injected, err := injectedContext(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to inject context: %w", err)
+	}
+	turns = session.InjectContext(turns, injected)
+
+	cmdCtx.Printf("Sending turn %d to %s and %s...\n", number, c.A, c.B)
+
+	// Both requests run at once; nothing is written to the session
+	models := []string{c.A, c.B}
+	responses := make([]bedrock.Response, len(models))
+	errs := make([]error, len(models))
+	var wg sync.WaitGroup
+	for i, model := range models {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i], errs[i] = bedrock.SendToModel(model, turns)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("failed to get response from %s: %w", models[i], err)
+		}
+	}
+
+	for i, r := range responses {
+		fmt.Printf("%s: %s input, %s output tokens, %s\n", models[i],
+			formatThousands(r.InputTokens), formatThousands(r.OutputTokens), responseCost(r))
+	}
+	fmt.Println()
+
+	diff := textdiff.Unified(c.A, c.B, responses[0].Text, responses[1].Text)
+	if diff == "" {
+		fmt.Println("Responses are identical")
+		return nil
+	}
+	fmt.Print(diff)
+	return nil
+}
+
+// responseCost estimates what a response cost, or "?" when its model has no
+// known price
+func responseCost(r bedrock.Response) string {
+	price, ok := analytics.PriceFor(r.ModelID)
+	if !ok {
+		return "$?"
+	}
+	cost := (float64(r.InputTokens)*price.Input + float64(r.OutputTokens)*price.Output) / 1_000_000
+	return fmt.Sprintf("$%.4f", cost)
+}
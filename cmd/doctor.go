@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/rana/ask/internal/bedrock"
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+)
+
+// DoctorCmd checks the Ask setup and reports problems
+type DoctorCmd struct {
+	Offline bool `help:"Skip checks that call AWS"`
+}
+
+// doctorReport counts check results as they are printed
+type doctorReport struct {
+	passed, warned, failed int
+}
+
+func (r *doctorReport) pass(name, format string, a ...interface{}) {
+	r.passed++
+	fmt.Printf("✓ %-18s %s\n", name, fmt.Sprintf(format, a...))
+}
+
+func (r *doctorReport) warn(name, format string, a ...interface{}) {
+	r.warned++
+	fmt.Printf("⚠ %-18s %s\n", name, fmt.Sprintf(format, a...))
+}
+
+func (r *doctorReport) fail(name, format string, a ...interface{}) {
+	r.failed++
+	fmt.Printf("✗ %-18s %s\n", name, fmt.Sprintf(format, a...))
+}
+
+// Run executes the doctor command
+func (c *DoctorCmd) Run(cmdCtx *Context) error {
+	r := &doctorReport{}
+
+	// Config is checked before Load, which would create a missing one
+	cfg := c.checkConfig(r)
+	if cfg == nil {
+		cfg = config.Defaults()
+	}
+	checkCacheDir(r)
+
+	// Resolving a model alias lists models, so it needs AWS too
+	if c.Offline {
+		r.warn("AWS", "skipped (--offline)")
+	} else if c.checkCredentials(cmdCtx, r) {
+		if modelID, err := cfg.ResolveModel(); err != nil {
+			r.fail("Model", "%s doesn't resolve: %v", cfg.Model, err)
+		} else {
+			r.pass("Model", "%s → %s", cfg.Model, modelID)
+			c.checkBedrock(cmdCtx, r, modelID)
+		}
+	}
+
+	path := cmdCtx.SessionPath("")
+	if _, err := os.Stat(path); err != nil {
+		r.warn("Session", "no %s here. Run: ask init", path)
+	} else if content, err := os.ReadFile(path); err != nil {
+		r.fail("Session", "failed to read %s: %v", path, err)
+	} else if turns, err := parseTurns(string(content)); err != nil {
+		r.fail("Session", "%s doesn't parse: %v", path, err)
+	} else {
+		r.pass("Session", "%s (%d turns)", path, len(turns))
+	}
+	checkLeftovers(r, path)
+
+	fmt.Printf("\n%d passed, %d warnings, %d failed\n", r.passed, r.warned, r.failed)
+	if r.failed > 0 {
+		return fmt.Errorf("%d check(s) failed", r.failed)
+	}
+	return nil
+}
+
+// checkConfig checks that cfg.toml exists, parses, validates and is the
+// current version, returning it when it can be used
+func (c *DoctorCmd) checkConfig(r *doctorReport) *config.Config {
+	path := config.ConfigPath()
+	if _, err := os.Stat(path); err != nil {
+		r.fail("Config", "%s not found. Any ask command creates it with defaults", path)
+		return nil
+	}
+
+	var raw config.Config
+	if _, err := toml.DecodeFile(path, &raw); err != nil {
+		r.fail("Config", "%s doesn't parse: %v", path, err)
+		return nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		r.fail("Config", "%v", err)
+		return nil
+	}
+	if err := cfg.Validate(); err != nil {
+		r.fail("Config", "%v", err)
+		return cfg
+	}
+	r.pass("Config", "%s", path)
+
+	switch {
+	case raw.Version < config.CurrentVersion:
+		r.warn("Config version", "%d is older than %d; it is migrated on the next load", raw.Version, config.CurrentVersion)
+	case raw.Version > config.CurrentVersion:
+		r.warn("Config version", "%d is newer than this ask understands (%d). Update ask", raw.Version, config.CurrentVersion)
+	default:
+		r.pass("Config version", "%d", raw.Version)
+	}
+	return cfg
+}
+
+// checkCacheDir checks that profile and model caches can be written
+func checkCacheDir(r *doctorReport) {
+	dir := config.CachePath()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		r.fail("Cache", "can't create %s: %v", dir, err)
+		return
+	}
+	file, err := os.CreateTemp(dir, "doctor-*")
+	if err != nil {
+		r.fail("Cache", "%s is not writable: %v", dir, err)
+		return
+	}
+	file.Close()
+	os.Remove(file.Name())
+	r.pass("Cache", "%s is writable", dir)
+}
+
+// checkCredentials reports whether AWS credentials can be retrieved
+func (c *DoctorCmd) checkCredentials(cmdCtx *Context, r *doctorReport) bool {
+	ctx, cancel := context.WithTimeout(cmdCtx.Context, 15*time.Second)
+	defer cancel()
+
+	awsCfg, err := config.LoadAWSConfig(ctx)
+	if err != nil {
+		r.fail("AWS credentials", "not configured: %v. Run: aws configure", err)
+		return false
+	}
+	creds, err := awsCfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		r.fail("AWS credentials", "%v. Run: aws configure (or aws sso login)", err)
+		return false
+	}
+	r.pass("AWS credentials", "%s (region %s)", creds.Source, awsCfg.Region)
+	return true
+}
+
+// checkBedrock checks the inference profile and sends a one-token request
+func (c *DoctorCmd) checkBedrock(cmdCtx *Context, r *doctorReport, modelID string) {
+	cached, ok := bedrock.CachedProfileARN(modelID)
+
+	ctx, cancel := context.WithTimeout(cmdCtx.Context, 30*time.Second)
+	defer cancel()
+	_, profileArn, err := bedrock.Preflight(ctx, "")
+
+	switch {
+	case ok:
+		r.pass("Inference profile", "%s (cached)", cached)
+	case profileArn != "":
+		r.pass("Inference profile", "%s (discovered)", profileArn)
+	default:
+		r.fail("Inference profile", "none found for %s", modelID)
+	}
+
+	if err != nil {
+		r.fail("Bedrock access", "%v", err)
+	} else {
+		r.pass("Bedrock access", "one-token request succeeded")
+	}
+}
+
+// checkLeftovers warns about temporary files an interrupted command left behind
+func checkLeftovers(r *doctorReport, sessionPath string) {
+	// Atomic writes go through ".<name>.<random>.tmp" beside the file
+	var found []string
+	for _, path := range []string{config.ConfigPath(), sessionPath} {
+		temps, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp"))
+		found = append(found, temps...)
+	}
+	if _, err := os.Stat(session.PartialPath(sessionPath)); err == nil {
+		found = append(found, session.PartialPath(sessionPath))
+	}
+
+	if len(found) == 0 {
+		r.pass("Leftover files", "none")
+		return
+	}
+	for _, path := range found {
+		if path == session.PartialPath(sessionPath) {
+			r.warn("Leftover files", "%s from an interrupted response. Run: ask resume", path)
+		} else {
+			r.warn("Leftover files", "%s from an interrupted write; safe to delete", path)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+)
+
+// ExecLastCmd runs shell blocks from the last AI turn
+type ExecLastCmd struct {
+	Block  int  `help:"Run only the Nth shell block (1-based)"`
+	DryRun bool `help:"Print commands without running them"`
+}
+
+// Run executes the exec-last command
+func (c *ExecLastCmd) Run(cmdCtx *Context) error {
+	path := cmdCtx.SessionPath("")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", path)
+		}
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	turns, err := parseTurns(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	// Find the last AI turn
+	lastAIIndex := -1
+	for i := len(turns) - 1; i >= 0; i-- {
+		if turns[i].Role == "AI" {
+			lastAIIndex = i
+			break
+		}
+	}
+
+	if lastAIIndex == -1 {
+		return fmt.Errorf("no AI turn found in %s", path)
+	}
+
+	blocks := session.ExtractCodeBlocks(turns[lastAIIndex].Content)
+
+	shellCount := 0
+	for _, block := range blocks {
+		if block.IsShell() {
+			shellCount++
+		}
+	}
+
+	if shellCount == 0 {
+		return fmt.Errorf("no bash or sh blocks in turn %d", turns[lastAIIndex].Number)
+	}
+	if c.Block < 0 || c.Block > shellCount {
+		return fmt.Errorf("block must be between 1 and %d", shellCount)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	runAll := false
+	shellIndex := 0
+
+	for _, block := range blocks {
+		if !block.IsShell() {
+			if c.Block == 0 {
+				lang := block.Lang
+				if lang == "" {
+					lang = "plain"
+				}
+				fmt.Printf("Skipping %s block:\n%s\n\n", lang, block.Content)
+			}
+			continue
+		}
+
+		shellIndex++
+		if c.Block != 0 && shellIndex != c.Block {
+			continue
+		}
+
+		fmt.Printf("[%d/%d] %s:\n%s\n", shellIndex, shellCount, block.Lang, block.Content)
+
+		if c.DryRun {
+			fmt.Println()
+			continue
+		}
+
+		if !runAll {
+			answer, err := prompt(reader, "Run? [y/n/a(ll)] ")
+			if err != nil {
+				return err
+			}
+			switch answer {
+			case "y", "yes":
+			case "a", "all":
+				runAll = true
+			default:
+				fmt.Println("Skipped")
+				fmt.Println()
+				continue
+			}
+		}
+
+		exitCode, err := runShellBlock(block)
+		if err != nil {
+			return err
+		}
+		if err := logExec(block, exitCode); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: couldn't write exec log: %v\n", err)
+		}
+
+		fmt.Printf("Exit code: %d\n\n", exitCode)
+	}
+
+	return nil
+}
+
+// prompt reads a single lower-cased answer from the reader
+func prompt(reader *bufio.Reader, question string) (string, error) {
+	fmt.Print(question)
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	return strings.ToLower(strings.TrimSpace(answer)), nil
+}
+
+// runShellBlock runs the block with its interpreter and returns the exit code
+func runShellBlock(block session.CodeBlock) (int, error) {
+	cmd := exec.Command(block.Lang, "-c", block.Content)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return 0, fmt.Errorf("failed to run %s: %w", block.Lang, err)
+	}
+
+	return 0, nil
+}
+
+// logExec appends an executed block and its exit code to ~/.ask/exec.log
+func logExec(block session.CodeBlock, exitCode int) error {
+	path := config.ExecLogPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	entry := fmt.Sprintf("[%s] %s (exit %d)\n%s\n\n",
+		time.Now().Format(time.RFC3339), block.Lang, exitCode, block.Content)
+	_, err = file.WriteString(entry)
+	return err
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rana/ask/internal/output"
+)
+
+// ExportCmd writes the session in a format other tools read
+type ExportCmd struct {
+	Format  string `required:"" enum:"obsidian" help:"Output format: obsidian"`
+	Vault   string `type:"existingdir" help:"Obsidian vault to write notes into"`
+	Name    string `help:"Folder and note name for the session (default: the session's directory name)"`
+	Session string `help:"Session file to export (default: session.md)"`
+}
+
+// Run executes the export command
+func (c *ExportCmd) Run(cmdCtx *Context) error {
+	if c.Vault == "" {
+		return fmt.Errorf("--vault is required for the obsidian format")
+	}
+
+	path := cmdCtx.SessionPath(c.Session)
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	turns, err := parseTurns(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	name := c.Name
+	if name == "" {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+		name = filepath.Base(filepath.Dir(abs))
+	}
+
+	dir, notes, err := output.ExportObsidian(c.Vault, name, string(content), turns, info.ModTime())
+	if err != nil {
+		return err
+	}
+
+	cmdCtx.Printf("Exported %d notes to %s\n", notes, dir)
+	return nil
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rana/ask/internal/git"
+	"github.com/rana/ask/internal/session"
+)
+
+// ExtractCmd writes code blocks from an AI turn to files
+type ExtractCmd struct {
+	Turn   int  `help:"AI turn to extract from (default: last)"`
+	Apply  bool `help:"Write blocks to the paths named by their '// file:' comments"`
+	DryRun bool `help:"Show what would be written without writing"`
+}
+
+// extraction pairs a code block with its destination
+type extraction struct {
+	block  session.CodeBlock
+	target string
+}
+
+// Run executes the extract command
+func (c *ExtractCmd) Run(cmdCtx *Context) error {
+	path := cmdCtx.SessionPath("")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", path)
+		}
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	turns, err := parseTurns(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	var turn *session.Turn
+	for i := len(turns) - 1; i >= 0; i-- {
+		if turns[i].Role == "AI" && (c.Turn == 0 || turns[i].Number == c.Turn) {
+			turn = &turns[i]
+			break
+		}
+	}
+	if turn == nil {
+		if c.Turn != 0 {
+			return fmt.Errorf("AI turn %d not found in %s", c.Turn, path)
+		}
+		return fmt.Errorf("no AI turn found in %s", path)
+	}
+
+	blocks := session.ExtractCodeBlocks(turn.Content)
+	if len(blocks) == 0 {
+		return fmt.Errorf("no code blocks in turn %d", turn.Number)
+	}
+
+	root := ""
+	if c.Apply {
+		if root, err = projectRoot(path); err != nil {
+			return err
+		}
+	}
+
+	var plan []extraction
+	for i, block := range blocks {
+		if !c.Apply {
+			plan = append(plan, extraction{block, generatedName(turn.Number, i+1, block.Lang)})
+			continue
+		}
+
+		target := block.FilePath()
+		if target == "" {
+			fmt.Printf("Skipping block %d: no '// file:' comment\n", i+1)
+			continue
+		}
+		if err := checkExtractPath(target); err != nil {
+			return err
+		}
+		plan = append(plan, extraction{block, filepath.Join(root, target)})
+	}
+
+	if len(plan) == 0 {
+		return fmt.Errorf("no blocks in turn %d name a file", turn.Number)
+	}
+
+	if c.Apply {
+		fmt.Printf("Turn %d, into %s:\n", turn.Number, root)
+	} else {
+		fmt.Printf("Turn %d:\n", turn.Number)
+	}
+	for _, e := range plan {
+		action := "create"
+		if _, err := os.Stat(e.target); err == nil {
+			action = "overwrite"
+		}
+		lines := strings.Count(e.block.Content, "\n") + 1
+		fmt.Printf("  %-9s %s (%d lines)\n", action, e.target, lines)
+	}
+
+	if c.DryRun {
+		return nil
+	}
+
+	if c.Apply {
+		answer, err := prompt(bufio.NewReader(os.Stdin), fmt.Sprintf("Write %d files? [y/n] ", len(plan)))
+		if err != nil {
+			return err
+		}
+		if answer != "y" && answer != "yes" {
+			fmt.Println("Nothing written")
+			return nil
+		}
+	}
+
+	for _, e := range plan {
+		if err := os.MkdirAll(filepath.Dir(e.target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", e.target, err)
+		}
+		if err := session.WriteAtomic(e.target, []byte(e.block.Content+"\n")); err != nil {
+			return fmt.Errorf("failed to write %s: %w", e.target, err)
+		}
+	}
+
+	fmt.Printf("Wrote %d files\n", len(plan))
+	return nil
+}
+
+// projectRoot returns the root of the git repository being worked in, or
+// the directory of the session at path outside a repository
+func projectRoot(path string) (string, error) {
+	if wd, err := os.Getwd(); err == nil {
+		if root, err := git.RepoRoot(wd); err == nil {
+			return root, nil
+		}
+	}
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	return dir, nil
+}
+
+// checkExtractPath rejects paths that would escape the project root
+func checkExtractPath(target string) error {
+	clean := filepath.Clean(target)
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("refusing to write '%s' outside the project", target)
+	}
+	return nil
+}
+
+// generatedName names an extracted block by turn, position and language
+func generatedName(turnNumber, index int, lang string) string {
+	extensions := map[string]string{
+		"go":         "go",
+		"python":     "py",
+		"javascript": "js",
+		"typescript": "ts",
+		"rust":       "rs",
+		"bash":       "sh",
+		"sh":         "sh",
+		"ruby":       "rb",
+		"markdown":   "md",
+		"yaml":       "yaml",
+		"json":       "json",
+		"toml":       "toml",
+		"sql":        "sql",
+	}
+
+	ext, ok := extensions[lang]
+	if !ok {
+		ext = "txt"
+	}
+	return fmt.Sprintf("turn%d-block%d.%s", turnNumber, index, ext)
+}
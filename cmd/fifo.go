@@ -0,0 +1,56 @@
+//go:build unix
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fifoWriter sends streamed chunks to a named pipe. Chunks are dropped while
+// no reader has the pipe open, so a reader can come and go without
+// interrupting the response.
+type fifoWriter struct {
+	path string
+	file *os.File
+}
+
+// createFIFO makes a named pipe at path, which Close removes
+func createFIFO(path string) (*fifoWriter, error) {
+	if err := syscall.Mkfifo(path, 0644); err != nil {
+		return nil, fmt.Errorf("failed to create fifo %s: %w", path, err)
+	}
+	return &fifoWriter{path: path}, nil
+}
+
+// Write passes p to the reader, if there is one. It never fails, since
+// losing the reader must not stop the session from being written.
+func (f *fifoWriter) Write(p []byte) (int, error) {
+	if f.file == nil {
+		// Opening without a reader fails with ENXIO instead of blocking
+		file, err := os.OpenFile(f.path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+		if err != nil {
+			return len(p), nil
+		}
+		f.file = file
+	}
+
+	if _, err := f.file.Write(p); err != nil {
+		if errors.Is(err, syscall.EPIPE) {
+			// The reader went away; try again with the next chunk
+			f.file.Close()
+			f.file = nil
+		}
+	}
+	return len(p), nil
+}
+
+// Close closes the pipe and removes it
+func (f *fifoWriter) Close() error {
+	if f.file != nil {
+		f.file.Close()
+	}
+	return os.Remove(f.path)
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package cmd
+
+import "fmt"
+
+// fifoWriter is unavailable without named pipes
+type fifoWriter struct{}
+
+func createFIFO(path string) (*fifoWriter, error) {
+	return nil, fmt.Errorf("--fifo needs named pipes, which this platform doesn't support")
+}
+
+func (f *fifoWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func (f *fifoWriter) Close() error { return nil }
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rana/ask/internal/expand"
+)
+
+// FilesCmd lists [[…]] references across all human turns
+type FilesCmd struct {
+	Session string `help:"Session file path (default: session.md)"`
+	Check   bool   `help:"Exit with an error if any reference is unresolvable"`
+}
+
+// Run executes the files command
+func (c *FilesCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	content, err := os.ReadFile(c.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", c.Session)
+		}
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+
+	turns, err := parseTurns(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var refs []string
+	for _, turn := range turns {
+		if turn.Role != "Human" {
+			continue
+		}
+		for _, match := range expand.ReferencePattern.FindAllStringSubmatch(turn.Content, -1) {
+			if !seen[match[1]] && !expand.IsFootnote(match[1]) && match[1] != expand.StdinReference {
+				seen[match[1]] = true
+				refs = append(refs, match[1])
+			}
+		}
+	}
+
+	if len(refs) == 0 {
+		fmt.Printf("No references in %s\n", c.Session)
+		return nil
+	}
+
+	missing := 0
+	for _, ref := range refs {
+		if !printReference(ref) {
+			missing++
+		}
+	}
+
+	if c.Check && missing > 0 {
+		return fmt.Errorf("%d unresolved reference(s)", missing)
+	}
+
+	return nil
+}
+
+// printReference prints a reference with its status and reports whether it resolves
+func printReference(ref string) bool {
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		fmt.Printf("⚠ %s (uncached)\n", ref)
+		return true
+	}
+
+	if strings.HasPrefix(ref, expand.DepsPrefix) {
+		file, _, err := expand.ParseDepsSpec(ref)
+		if err != nil {
+			fmt.Printf("✗ %s (%v)\n", ref, err)
+			return false
+		}
+		if _, err := os.Stat(file); err != nil {
+			fmt.Printf("✗ %s (not found)\n", ref)
+			return false
+		}
+		fmt.Printf("✓ %s (imports)\n", ref)
+		return true
+	}
+
+	if !strings.HasSuffix(ref, "/") && expand.IsGlob(ref) {
+		files, err := expand.GlobFiles(ref)
+		if err != nil {
+			fmt.Printf("✗ %s (%v)\n", ref, err)
+			return false
+		}
+		fmt.Printf("✓ %s (%d files)\n", ref, len(files))
+		return true
+	}
+
+	path, lines, err := expand.ParseLineRange(strings.TrimSuffix(strings.TrimSuffix(ref, "/**/"), "/"))
+	if err != nil {
+		fmt.Printf("✗ %s (%v)\n", ref, err)
+		return false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Printf("✗ %s (not found)\n", ref)
+		} else {
+			fmt.Printf("✗ %s (%v)\n", ref, err)
+		}
+		return false
+	}
+
+	isDirRef := strings.HasSuffix(ref, "/")
+	switch {
+	case isDirRef && !info.IsDir():
+		fmt.Printf("✗ %s (not a directory)\n", ref)
+		return false
+	case !isDirRef && info.IsDir():
+		fmt.Printf("✗ %s (is a directory, use [[%s/]])\n", ref, path)
+		return false
+	case info.IsDir():
+		fmt.Printf("✓ %s (directory)\n", ref)
+	case lines != nil:
+		fmt.Printf("✓ %s (lines %s of %s)\n", ref, lines, formatSize(info.Size()))
+	default:
+		fmt.Printf("✓ %s (%s)\n", ref, formatSize(info.Size()))
+	}
+
+	return true
+}
+
+// formatSize renders a byte count as B, KB or MB
+func formatSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1fKB", float64(size)/1024)
+	}
+	return fmt.Sprintf("%dB", size)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/expand"
+	"github.com/rana/ask/internal/filter"
+)
+
+const (
+	ansiRed    = "\033[31m"
+	ansiYellow = "\033[33m"
+	ansiReset  = "\033[0m"
+)
+
+// FilterTestCmd shows which lines of a file content filtering would remove
+type FilterTestCmd struct {
+	File    string `arg:"" type:"existingfile" help:"File to filter"`
+	Section string `enum:"all,header,comments" default:"all" help:"Show only one stage: header or comments"`
+}
+
+// Run executes the filter-test command
+func (c *FilterTestCmd) Run(cmdCtx *Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	content, err := os.ReadFile(c.File)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", c.File, err)
+	}
+
+	// A single stage is shown even when the config disables it, to preview it
+	stripHeaders := cfg.Filter.Enabled && cfg.Filter.StripHeaders
+	stripComments := cfg.Filter.Enabled && cfg.Filter.StripAllComments
+	switch c.Section {
+	case "header":
+		stripHeaders, stripComments = true, false
+	case "comments":
+		stripHeaders, stripComments = false, true
+	}
+
+	lines := filter.Explain(string(content), expand.LanguageHint(c.File), cfg.Filter.Header, stripHeaders, stripComments)
+
+	color := useColor()
+	removed := map[filter.Stage]int{}
+	for _, line := range lines {
+		removed[line.RemovedBy]++
+		printFilterLine(line, color)
+	}
+
+	fmt.Println()
+	switch c.Section {
+	case "header":
+		fmt.Printf("%d lines: %d removed as header\n", len(lines), removed[filter.Header])
+	case "comments":
+		fmt.Printf("%d lines: %d removed as comments\n", len(lines), removed[filter.Comments])
+	default:
+		fmt.Printf("%d lines: %d removed as header, %d as comments, %d kept\n",
+			len(lines), removed[filter.Header], removed[filter.Comments], removed[filter.Kept])
+		if !cfg.Filter.Enabled {
+			fmt.Println("Filtering is disabled. Enable with: ask cfg filter enable on")
+		}
+	}
+	if len(cfg.Filter.Rules) > 0 {
+		fmt.Printf("%d filter rule(s) run afterwards and are not shown\n", len(cfg.Filter.Rules))
+	}
+
+	return nil
+}
+
+// printFilterLine prints a line with a marker for the stage that removed it,
+// colored red for header and yellow for comments
+func printFilterLine(line filter.Line, color bool) {
+	marker, ansi := "  ", ""
+	switch line.RemovedBy {
+	case filter.Header:
+		marker, ansi = "-h", ansiRed
+	case filter.Comments:
+		marker, ansi = "-c", ansiYellow
+	}
+
+	if color && ansi != "" {
+		fmt.Printf("%s%4d %s %s%s\n", ansi, line.Number, marker, line.Text, ansiReset)
+		return
+	}
+	fmt.Printf("%4d %s %s\n", line.Number, marker, line.Text)
+}
+
+// useColor reports whether stdout is a terminal that accepts ANSI colors
+func useColor() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
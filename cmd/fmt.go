@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rana/ask/internal/session"
+)
+
+// FmtCmd normalizes an AI turn in session.md
+type FmtCmd struct {
+	Turn  int  `help:"AI turn number to format (default: last)"`
+	Check bool `help:"Exit non-zero if formatting would change anything"`
+}
+
+// Run executes the fmt command
+func (c *FmtCmd) Run(cmdCtx *Context) error {
+	path := cmdCtx.SessionPath("")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", path)
+		}
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	format := sessionFormat()
+	turns, err := format.Parse(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	// Resolve the target AI turn
+	var target *session.Turn
+	for i := len(turns) - 1; i >= 0; i-- {
+		if turns[i].Role != "AI" {
+			continue
+		}
+		if c.Turn == 0 || turns[i].Number == c.Turn {
+			target = &turns[i]
+			break
+		}
+	}
+
+	if target == nil {
+		if c.Turn != 0 {
+			return fmt.Errorf("turn %d is not an AI turn", c.Turn)
+		}
+		return fmt.Errorf("no AI turn found in %s", path)
+	}
+
+	turnNumber := target.Number
+	var formatted string
+	if session.IsNative(format) {
+		if formatted, err = session.FormatAITurn(string(content), turnNumber); err != nil {
+			return err
+		}
+	} else {
+		formatted = session.ReplaceTurnContent(string(content), *target, session.NormalizeResponse(target.Raw))
+	}
+
+	if formatted == string(content) {
+		fmt.Printf("Turn %d already formatted\n", turnNumber)
+		return nil
+	}
+
+	if c.Check {
+		return fmt.Errorf("turn %d needs formatting. Run 'ask fmt --turn=%d'", turnNumber, turnNumber)
+	}
+
+	if err := session.WriteAtomic(path, []byte(formatted)); err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+
+	fmt.Printf("Formatted turn %d\n", turnNumber)
+	return nil
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// grepContext is the number of lines shown around each match
+const grepContext = 2
+
+var turnHeader = regexp.MustCompile(`^# \[(\d+)\] (Human|AI)\b`)
+
+// GrepCmd searches the lines of a session, showing the turn of each match
+type GrepCmd struct {
+	Pattern string `arg:"" help:"Text to search for"`
+	Session string `help:"Session file path (default: session.md)"`
+	Turn    int    `help:"Only search turn N"`
+	Regex   bool   `help:"Treat the pattern as a regular expression"`
+	Count   bool   `help:"Print only the number of matching lines"`
+}
+
+// grepLine is a session line with the turn it belongs to
+type grepLine struct {
+	number int
+	turn   int
+	role   string
+	text   string
+}
+
+// Run executes the grep command
+func (c *GrepCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	content, err := os.ReadFile(c.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", c.Session)
+		}
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+
+	expr := regexp.QuoteMeta(c.Pattern)
+	if c.Regex {
+		expr = c.Pattern
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	lines := sessionLines(string(content))
+	if c.Turn != 0 {
+		var inTurn []grepLine
+		for _, line := range lines {
+			if line.turn == c.Turn {
+				inTurn = append(inTurn, line)
+			}
+		}
+		if len(inTurn) == 0 {
+			return fmt.Errorf("no turn %d in %s", c.Turn, c.Session)
+		}
+		lines = inTurn
+	}
+
+	var matches []int
+	for i, line := range lines {
+		if pattern.MatchString(line.text) {
+			matches = append(matches, i)
+		}
+	}
+
+	if c.Count {
+		fmt.Println(len(matches))
+		return nil
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no lines matching %q in %s", c.Pattern, c.Session)
+	}
+
+	color := useColor()
+	last := -1
+	for _, m := range matches {
+		start := max(m-grepContext, last+1)
+		end := min(m+grepContext, len(lines)-1)
+		if last >= 0 && start > last+1 {
+			fmt.Println("--")
+		}
+		for i := start; i <= end; i++ {
+			printGrepLine(lines[i], pattern, color)
+		}
+		last = max(last, end)
+	}
+	return nil
+}
+
+// sessionLines splits content into lines tagged with their turn.
+// Lines before the first turn header have turn 0.
+func sessionLines(content string) []grepLine {
+	var lines []grepLine
+	turn, role := 0, ""
+	for i, text := range strings.Split(content, "\n") {
+		if m := turnHeader.FindStringSubmatch(text); m != nil {
+			turn, _ = strconv.Atoi(m[1])
+			role = m[2]
+		}
+		lines = append(lines, grepLine{number: i + 1, turn: turn, role: role, text: text})
+	}
+	return lines
+}
+
+// printGrepLine prints a line prefixed with its turn, marking matches with ':'
+// and context with '-' like grep does
+func printGrepLine(line grepLine, pattern *regexp.Regexp, color bool) {
+	prefix := "[-]"
+	if line.turn != 0 {
+		prefix = fmt.Sprintf("[%d %s]", line.turn, line.role)
+	}
+	sep := "-"
+	text := line.text
+	if pattern.MatchString(text) {
+		sep = ":"
+		if color {
+			text = pattern.ReplaceAllStringFunc(text, func(s string) string {
+				return "\033[1m" + s + "\033[0m"
+			})
+		}
+	}
+	fmt.Printf("%s %d%s %s\n", prefix, line.number, sep, text)
+}
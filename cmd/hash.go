@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rana/ask/internal/session"
+)
+
+// HashCmd prints SHA256 hashes of session turns
+type HashCmd struct {
+	Session string `help:"Session file path (default: session.md)"`
+	Turn    int    `help:"Hash only this turn"`
+	Verify  string `help:"Compare against a saved hash file" type:"path"`
+}
+
+// Run executes the hash command
+func (c *HashCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	content, err := os.ReadFile(c.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", c.Session)
+		}
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+
+	turns, err := parseTurns(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	var lines []string
+	if c.Turn != 0 {
+		hash := session.HashTurn(turns, c.Turn)
+		if hash == "" {
+			return fmt.Errorf("turn %d not found in %s", c.Turn, c.Session)
+		}
+		for _, turn := range turns {
+			if turn.Number == c.Turn {
+				lines = append(lines, session.HashLine(turn, hash))
+				break
+			}
+		}
+	} else {
+		lines = session.HashLines(turns)
+	}
+
+	if c.Verify == "" {
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		return nil
+	}
+
+	return verifyHashes(lines, c.Verify, c.Turn)
+}
+
+// verifyHashes compares computed hash lines against a saved hash file.
+// A non-zero turn limits verification to that turn.
+func verifyHashes(lines []string, path string, turn int) error {
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read hash file: %w", err)
+	}
+
+	// Index saved hashes by "turn N role" prefix, in file order since
+	// duplicate turn numbers share a prefix
+	expected := make(map[string][]string)
+	for _, line := range strings.Split(string(saved), "\n") {
+		line = strings.TrimSpace(line)
+		prefix, hash, ok := strings.Cut(line, " SHA256:")
+		if !ok {
+			continue
+		}
+		if turn != 0 && !strings.HasPrefix(prefix, fmt.Sprintf("turn %d ", turn)) {
+			continue
+		}
+		expected[prefix] = append(expected[prefix], hash)
+	}
+
+	mismatches := 0
+	for _, line := range lines {
+		prefix, hash, _ := strings.Cut(line, " SHA256:")
+		want := expected[prefix]
+		switch {
+		case len(want) == 0:
+			fmt.Printf("%s: not in hash file\n", prefix)
+		case want[0] != hash:
+			fmt.Printf("%s: MISMATCH\n", prefix)
+			mismatches++
+		default:
+			fmt.Printf("%s: OK\n", prefix)
+		}
+		if len(want) > 0 {
+			expected[prefix] = want[1:]
+		}
+	}
+
+	// Turns present at save time that no longer exist indicate truncation
+	var missing []string
+	for prefix, hashes := range expected {
+		for range hashes {
+			missing = append(missing, prefix)
+		}
+	}
+	sort.Strings(missing)
+	for _, prefix := range missing {
+		fmt.Printf("%s: missing from session\n", prefix)
+		mismatches++
+	}
+
+	if mismatches > 0 {
+		return fmt.Errorf("%d turn(s) failed verification", mismatches)
+	}
+	return nil
+}
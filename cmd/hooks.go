@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// hookEnv describes the chat a hook runs for
+type hookEnv struct {
+	SessionPath string
+	TurnNumber  int
+	Model       string
+}
+
+// runHook runs command in the shell with the chat described in the
+// ASK_SESSION_PATH, ASK_TURN_NUMBER and ASK_MODEL environment variables,
+// returning its combined output
+func runHook(command string, env hookEnv) (string, error) {
+	// Hooks may change directory, so the path is made absolute
+	if abs, err := filepath.Abs(env.SessionPath); err == nil {
+		env.SessionPath = abs
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(),
+		"ASK_SESSION_PATH="+env.SessionPath,
+		fmt.Sprintf("ASK_TURN_NUMBER=%d", env.TurnNumber),
+		"ASK_MODEL="+env.Model,
+	)
+
+	output, err := cmd.CombinedOutput()
+	return strings.TrimRight(string(output), "\n"), err
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rana/ask/internal/session"
+)
+
+// ImportCmd creates a session from an exported conversation
+type ImportCmd struct {
+	Format  string `required:"" enum:"openai-chat,claude-export,ask-json" help:"Input format: openai-chat, claude-export or ask-json"`
+	File    string `arg:"" type:"existingfile" help:"Exported conversation JSON"`
+	Session string `help:"Session file to create (default: session.md)"`
+}
+
+// Run executes the import command
+func (c *ImportCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	if _, err := os.Stat(c.Session); err == nil {
+		return fmt.Errorf("%s already exists. Delete it to import", c.Session)
+	}
+
+	data, err := os.ReadFile(c.File)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", c.File, err)
+	}
+
+	turns, err := session.ParseImport(data, c.Format)
+	if err != nil {
+		return fmt.Errorf("failed to import %s: %w", c.File, err)
+	}
+
+	if err := session.WriteAtomic(c.Session, []byte(renderTurns(turns))); err != nil {
+		return fmt.Errorf("failed to create %s: %w", c.Session, err)
+	}
+
+	fmt.Printf("Imported %d turns into %s\n", len(turns), c.Session)
+	return nil
+}
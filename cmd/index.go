@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+)
+
+// firstWordsCount is how many words of the first question the index keeps
+const firstWordsCount = 10
+
+// IndexCmd manages the session index ask search queries
+type IndexCmd struct {
+	Rebuild IndexRebuildCmd `cmd:"" help:"Regenerate the index from scratch"`
+	Status  IndexStatusCmd  `cmd:"" help:"Show how up to date the index is"`
+}
+
+// IndexRebuildCmd regenerates ~/.ask/sessions.toml
+type IndexRebuildCmd struct {
+	Paths []string `arg:"" optional:"" type:"path" help:"Session files or directories (default: indexed sessions, the session, the global session and the archive)"`
+}
+
+// Run executes the index rebuild command
+func (c *IndexRebuildCmd) Run(cmdCtx *Context) error {
+	var count int
+	err := session.UpdateSessionIndex(config.SessionIndexPath(), func(index *session.SessionIndex) error {
+		old := *index
+		index.Sessions = nil
+
+		paths := c.Paths
+		if len(paths) == 0 {
+			for _, e := range old.Sessions {
+				paths = append(paths, e.Path)
+			}
+			paths = append(paths, cmdCtx.SessionPath(""), config.GlobalSessionPath(), config.ArchivePath())
+		}
+
+		for _, root := range paths {
+			err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					if os.IsNotExist(err) && path == root && len(c.Paths) == 0 {
+						return nil // Deleted sessions and default locations that don't exist yet
+					}
+					return err
+				}
+				if d.IsDir() {
+					return nil
+				}
+
+				entry, err := indexEntry(path)
+				if err != nil {
+					return nil // Not a session, e.g. the archive index
+				}
+				// Models chat recorded aren't in the file unless it names one
+				if prev, ok := old.Find(entry.Path); ok {
+					entry.AddModels(prev.ModelsUsed...)
+				}
+				index.Update(entry)
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to index %s: %w", root, err)
+			}
+		}
+		count = len(index.Sessions)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Indexed %d sessions into %s\n", count, config.SessionIndexPath())
+	return nil
+}
+
+// IndexStatusCmd reports how many indexed sessions changed since indexing
+type IndexStatusCmd struct{}
+
+// Run executes the index status command
+func (c *IndexStatusCmd) Run(cmdCtx *Context) error {
+	path := config.SessionIndexPath()
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		fmt.Println("No session index yet. Run: ask index rebuild")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	index, err := session.LoadSessionIndex(path)
+	if err != nil {
+		return err
+	}
+
+	stale, missing := 0, 0
+	for _, e := range index.Sessions {
+		info, err := os.Stat(e.Path)
+		switch {
+		case err != nil:
+			missing++
+		case info.ModTime().After(e.LastModified):
+			stale++
+		}
+	}
+
+	fmt.Printf("Index:    %s\n", path)
+	fmt.Printf("Updated:  %s (%s ago)\n", info.ModTime().Format("2006-01-02 15:04"), time.Since(info.ModTime()).Round(time.Second))
+	fmt.Printf("Sessions: %d\n", len(index.Sessions))
+	fmt.Printf("Changed:  %d\n", stale)
+	fmt.Printf("Missing:  %d\n", missing)
+	if stale > 0 || missing > 0 {
+		fmt.Println("\nRun 'ask index rebuild' to refresh the index")
+	}
+	return nil
+}
+
+// indexEntry reads the session at path into an index entry
+func indexEntry(path string) (session.IndexEntry, error) {
+	var entry session.IndexEntry
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return entry, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+	content := string(data)
+
+	turns, err := parseTurns(content)
+	if err != nil {
+		return entry, err
+	}
+
+	if entry.Path, err = filepath.Abs(path); err != nil {
+		return entry, err
+	}
+	entry.LastModified = info.ModTime().UTC()
+	entry.TurnCount = len(turns)
+	entry.Tags = session.AllTags(content)
+
+	for _, match := range modelAnnotations.FindAllStringSubmatch(content, -1) {
+		entry.AddModels(match[1])
+	}
+
+	for _, turn := range turns {
+		if turn.Role == "Human" {
+			entry.FirstHumanWords = session.FirstWords(turn.Content, firstWordsCount)
+			break
+		}
+	}
+	return entry, nil
+}
+
+// sessionIndexMu serializes index updates of chats run in parallel by batch;
+// the file lock covers other processes
+var sessionIndexMu sync.Mutex
+
+// updateSessionIndex refreshes the index entry of the session at path,
+// adding model to the models it has used
+func updateSessionIndex(path, model string) error {
+	entry, err := indexEntry(path)
+	if err != nil {
+		return err
+	}
+
+	sessionIndexMu.Lock()
+	defer sessionIndexMu.Unlock()
+	return session.UpdateSessionIndex(config.SessionIndexPath(), func(index *session.SessionIndex) error {
+		if prev, ok := index.Find(entry.Path); ok {
+			entry.AddModels(prev.ModelsUsed...)
+		}
+		entry.AddModels(model)
+		index.Update(entry)
+		return nil
+	})
+}
@@ -1,30 +1,210 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/rana/ask/internal/config"
 	"github.com/rana/ask/internal/session"
 )
 
 // InitCmd initializes a new session
-type InitCmd struct{}
+type InitCmd struct {
+	Hash     bool   `help:"Also write session.md.sha256 with initial turn hashes"`
+	Template string `help:"Start from a named template in ~/.ask/templates instead of session.init_template"`
+
+	TrackBranch bool `help:"Keep a session per git branch, swapped in by a post-checkout hook"`
+}
 
 // Run executes the init command
 func (c *InitCmd) Run(cmdCtx *Context) error {
+	if c.TrackBranch {
+		return c.trackBranch(cmdCtx)
+	}
+
+	path := cmdCtx.SessionPath("")
+
+	// One reader for every prompt, so piped answers aren't lost to a buffer
+	reader := bufio.NewReader(os.Stdin)
+
 	// Check if session.md already exists
-	if _, err := os.Stat("session.md"); err == nil {
-		return fmt.Errorf("session.md already exists. Delete it to start fresh")
+	if info, err := os.Stat(path); err == nil {
+		if err := replaceStaleSession(reader, path, info.ModTime()); err != nil {
+			return err
+		}
 	}
 
 	// Create initial session content
-	content := "# [1] Human\n\n"
+	content, err := c.initialContent(reader)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
 
 	// Write session.md
-	if err := session.WriteAtomic("session.md", []byte(content)); err != nil {
-		return fmt.Errorf("failed to create session.md: %w", err)
+	if err := session.WriteAtomic(path, []byte(content)); err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	fmt.Printf("Created %s\n", path)
+
+	if c.Hash {
+		turns, err := parseTurns(content)
+		if err != nil {
+			return fmt.Errorf("failed to parse session: %w", err)
+		}
+		hashes := strings.Join(session.HashLines(turns), "\n") + "\n"
+		if err := session.WriteAtomic(path+".sha256", []byte(hashes)); err != nil {
+			return fmt.Errorf("failed to write %s.sha256: %w", path, err)
+		}
+		fmt.Printf("Created %s.sha256\n", path)
+	}
+
+	return nil
+}
+
+// initialContent returns the starting text of a new session: the --template
+// file, else session.init_template, else an empty first Human turn.
+// A nil reader leaves {{stdin:…}} placeholders for the user to fill in.
+func (c *InitCmd) initialContent(reader *bufio.Reader) (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = config.Defaults()
+	}
+
+	template := cfg.Session.InitTemplate
+	if c.Template != "" {
+		if template, err = loadNamedTemplate(c.Template); err != nil {
+			return "", err
+		}
+	}
+	if template == "" {
+		return sessionFormat().Header(1, "Human") + "\n\n", nil
+	}
+
+	content, err := promptTemplateValues(reader, expandInitTemplate(template, cfg))
+	if err != nil {
+		return "", err
+	}
+	if _, err := parseTurns(content); err != nil {
+		return "", fmt.Errorf("init template has no turns: %w", err)
+	}
+	return content, nil
+}
+
+// loadNamedTemplate reads ~/.ask/templates/name, with or without an extension
+func loadNamedTemplate(name string) (string, error) {
+	if strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid template name '%s'", name)
+	}
+
+	dir := config.TemplatesPath()
+	for _, candidate := range []string{name, name + ".md", name + filepath.Ext(config.SessionFileName())} {
+		content, err := os.ReadFile(filepath.Join(dir, candidate))
+		if err == nil {
+			return string(content), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read template '%s': %w", name, err)
+		}
+	}
+	return "", fmt.Errorf("no template '%s' in %s", name, dir)
+}
+
+// expandInitTemplate fills in {{DATE}}, {{CWD}}, {{USER}} and {{MODEL}}
+func expandInitTemplate(template string, cfg *config.Config) string {
+	cwd, _ := os.Getwd()
+	user := os.Getenv("USER")
+	if user == "" {
+		user = os.Getenv("USERNAME") // Windows
+	}
+
+	return strings.NewReplacer(
+		"{{DATE}}", time.Now().Format("2006-01-02"),
+		"{{CWD}}", cwd,
+		"{{USER}}", user,
+		"{{MODEL}}", cfg.Model,
+	).Replace(template)
+}
+
+// stdinPlaceholder matches {{stdin:Prompt text}} in an init template
+var stdinPlaceholder = regexp.MustCompile(`\{\{stdin:([^}]*)\}\}`)
+
+// promptTemplateValues asks for each {{stdin:…}} placeholder in turn and
+// substitutes the answer. A prompt used twice is asked once. With no reader
+// the placeholders are kept as they are.
+func promptTemplateValues(reader *bufio.Reader, template string) (string, error) {
+	if reader == nil || !stdinPlaceholder.MatchString(template) {
+		return template, nil
+	}
+
+	answers := make(map[string]string)
+	var promptErr error
+	content := stdinPlaceholder.ReplaceAllStringFunc(template, func(match string) string {
+		question := strings.TrimSpace(stdinPlaceholder.FindStringSubmatch(match)[1])
+		if answer, ok := answers[question]; ok || promptErr != nil {
+			return answer
+		}
+
+		fmt.Fprintf(os.Stderr, "%s: ", question)
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			promptErr = fmt.Errorf("failed to read answer to '%s': %w", question, err)
+			return ""
+		}
+		answers[question] = strings.TrimSpace(answer)
+		return answers[question]
+	})
+	if promptErr != nil {
+		return "", promptErr
+	}
+	return content, nil
+}
+
+// replaceStaleSession clears the way for a new session when the existing one
+// is older than session.stale_after, archiving it unless told to overwrite
+func replaceStaleSession(reader *bufio.Reader, path string, modTime time.Time) error {
+	staleAfter := 7 * 24 * time.Hour
+	if cfg, err := config.Load(); err == nil {
+		if age, err := config.ParseAge(cfg.Session.StaleAfter); err == nil {
+			staleAfter = age
+		}
+	}
+
+	age := time.Since(modTime)
+	if age < staleAfter {
+		return fmt.Errorf("%s already exists. Delete it to start fresh", path)
+	}
+
+	question := fmt.Sprintf("%s is %d days old. Archive it? [y/n/overwrite] ", path, int(age.Hours()/24))
+	answer, err := prompt(reader, question)
+	if err != nil {
+		return err
+	}
+
+	switch answer {
+	case "y", "yes":
+		root := config.ArchivePath()
+		entry, err := session.Archive(path, root)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Archived %s → %s\n", path, filepath.Join(root, entry.Path))
+	case "overwrite":
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("kept %s", path)
 	}
 
-	fmt.Println("Created session.md")
 	return nil
 }
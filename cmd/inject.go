@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rana/ask/internal/expand"
+	"github.com/rana/ask/internal/session"
+)
+
+// InjectCmd manages files sent as context with every chat request
+type InjectCmd struct {
+	Session string `help:"Session file path (default: session.md)"`
+	File    string `help:"File to send with every request"`
+	List    bool   `help:"List injected files (default)"`
+	Remove  string `help:"Stop injecting this file"`
+}
+
+// Run executes the inject command
+func (c *InjectCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	content, err := os.ReadFile(c.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", c.Session)
+		}
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+
+	var updated string
+	switch {
+	case c.File != "" && c.Remove != "":
+		return fmt.Errorf("use either --file or --remove, not both")
+
+	case c.File != "":
+		if strings.Contains(c.File, "-->") {
+			return fmt.Errorf("invalid file name '%s'", c.File)
+		}
+		info, err := os.Stat(c.File)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("cannot find '%s'", c.File)
+			}
+			return fmt.Errorf("failed to stat '%s': %w", c.File, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("'%s' is a directory. Inject files one at a time", c.File)
+		}
+		updated = session.AddInjection(string(content), c.File)
+		if updated == string(content) {
+			fmt.Printf("%s is already injected\n", c.File)
+			return nil
+		}
+
+	case c.Remove != "":
+		var removed bool
+		if updated, removed = session.RemoveInjection(string(content), c.Remove); !removed {
+			return fmt.Errorf("%s is not injected in %s", c.Remove, c.Session)
+		}
+
+	default:
+		files := session.ReadInjections(string(content))
+		if len(files) == 0 {
+			fmt.Println("No injected files. Add one with: ask inject --file=AGENTS.md")
+			return nil
+		}
+		for _, file := range files {
+			fmt.Println(file)
+		}
+		return nil
+	}
+
+	if err := session.WriteAtomic(c.Session, []byte(updated)); err != nil {
+		return fmt.Errorf("failed to update %s: %w", c.Session, err)
+	}
+	if c.File != "" {
+		fmt.Printf("Injecting %s into every request\n", c.File)
+	} else {
+		fmt.Printf("Stopped injecting %s\n", c.Remove)
+	}
+	return nil
+}
+
+// injectedContext expands the files injected into the session, returning
+// an empty string when there are none
+func injectedContext(content string) (string, error) {
+	files := session.ReadInjections(content)
+	if len(files) == 0 {
+		return "", nil
+	}
+
+	// A missing file fails the request rather than silently dropping context
+	var refs strings.Builder
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			return "", fmt.Errorf("cannot read injected file '%s'. Remove it with: ask inject --remove=%s", file, file)
+		}
+		fmt.Fprintf(&refs, "[[%s]]\n", file)
+	}
+
+	expanded, _, err := expand.ExpandReferences(refs.String(), 0, expand.Options{Quiet: true, FailFast: true})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
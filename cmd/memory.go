@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/memory"
+	"github.com/rana/ask/internal/session"
+)
+
+// MemoryCmd manages the index ask chat recalls earlier sessions from
+type MemoryCmd struct {
+	Index  MemoryIndexCmd  `cmd:"" help:"Rebuild the index from sessions"`
+	Search MemorySearchCmd `cmd:"" help:"Show the passages a query would recall"`
+	Clear  MemoryClearCmd  `cmd:"" help:"Delete the index"`
+}
+
+// MemoryIndexCmd rebuilds the memory index
+type MemoryIndexCmd struct {
+	Paths []string `arg:"" optional:"" type:"path" help:"Session files or directories (default: the session, the global session and the archive)"`
+}
+
+// Run executes the memory index command
+func (c *MemoryIndexCmd) Run(cmdCtx *Context) error {
+	paths := c.Paths
+	if len(paths) == 0 {
+		paths = []string{cmdCtx.SessionPath(""), config.GlobalSessionPath(), config.ArchivePath()}
+	}
+
+	index := &memory.Index{}
+	sessions := 0
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == root && len(c.Paths) == 0 {
+					return nil // Default locations that don't exist yet
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			turns, err := parseTurns(string(content))
+			if err != nil {
+				return nil // Not a session, e.g. the archive index
+			}
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			index.Add(abs, turns)
+			sessions++
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to index %s: %w", root, err)
+		}
+	}
+
+	if err := index.Save(config.MemoryPath()); err != nil {
+		return fmt.Errorf("failed to save memory index: %w", err)
+	}
+
+	fmt.Printf("Indexed %d passages from %d sessions into %s\n", len(index.Chunks), sessions, config.MemoryPath())
+	return nil
+}
+
+// MemorySearchCmd searches the memory index
+type MemorySearchCmd struct {
+	Query string `arg:"" help:"Text to search for"`
+	Top   int    `help:"Number of passages to show (default: memory.top_k)"`
+}
+
+// Run executes the memory search command
+func (c *MemorySearchCmd) Run(cmdCtx *Context) error {
+	index, err := memory.Load(config.MemoryPath())
+	if err != nil {
+		return err
+	}
+	if len(index.Chunks) == 0 {
+		return fmt.Errorf("memory index is empty. Run: ask memory index")
+	}
+
+	top := c.Top
+	if top <= 0 {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		top = cfg.Memory.Limit()
+	}
+
+	results := index.Search(c.Query, top, nil)
+	if len(results) == 0 {
+		return fmt.Errorf("no passages match '%s'", c.Query)
+	}
+
+	for i, r := range results {
+		fmt.Printf("%d. %.3f  %s [%d %s]\n", i+1, r.Score, r.Session, r.Turn, r.Role)
+		fmt.Printf("   %s\n\n", snippet(r.Text, 100))
+	}
+	return nil
+}
+
+// snippet collapses text to one line of at most n characters
+func snippet(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > n {
+		text = string(runes[:n-3]) + "..."
+	}
+	return text
+}
+
+// MemoryClearCmd deletes the memory index
+type MemoryClearCmd struct{}
+
+// Run executes the memory clear command
+func (c *MemoryClearCmd) Run(cmdCtx *Context) error {
+	if err := memory.Clear(config.MemoryPath()); err != nil {
+		return fmt.Errorf("failed to clear memory: %w", err)
+	}
+	fmt.Println("Memory cleared")
+	return nil
+}
+
+// recallMemory adds the passages most relevant to the last human turn to
+// it, leaving out those of path's turns that are being sent anyway
+func recallMemory(cmdCtx *Context, cfg *config.Config, path string, turns []session.Turn) error {
+	index, err := memory.Load(config.MemoryPath())
+	if err != nil {
+		return err
+	}
+	if len(index.Chunks) == 0 {
+		cmdCtx.Printf("Memory: index is empty. Run: ask memory index\n")
+		return nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	sent := make(map[int]bool)
+	for _, turn := range turns {
+		sent[turn.Number] = true
+	}
+
+	last := &turns[len(turns)-1]
+	if last.Role != "Human" {
+		return nil
+	}
+	results := index.Search(last.Content, cfg.Memory.Limit(), func(chunk memory.Chunk) bool {
+		return chunk.Session == abs && sent[chunk.Turn]
+	})
+	if len(results) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Passages from earlier sessions that may be relevant:\n\n")
+	for _, r := range results {
+		fmt.Fprintf(&b, "## %s [%d %s]\n\n%s\n\n", r.Session, r.Turn, r.Role, r.Text)
+	}
+	b.WriteString("---\n\n")
+	last.Content = b.String() + last.Content
+
+	cmdCtx.Printf("Memory: recalled %d passages\n", len(results))
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rana/ask/internal/expand"
+	"github.com/rana/ask/internal/session"
+)
+
+// MetaCmd prints session statistics as JSON for external tools
+type MetaCmd struct {
+	Session string `help:"Session file path (default: session.md)"`
+}
+
+// sessionMeta is the JSON document printed by ask meta
+type sessionMeta struct {
+	SessionPath      string     `json:"session_path"`
+	Created          *time.Time `json:"created"` // Earliest recorded expansion; null if none
+	LastModified     time.Time  `json:"last_modified"`
+	TurnCount        int        `json:"turn_count"`
+	HumanTurns       int        `json:"human_turns"`
+	AITurns          int        `json:"ai_turns"`
+	InterruptedTurns int        `json:"interrupted_turns"`
+	TotalChars       int        `json:"total_chars"`
+	ModelsUsed       []string   `json:"models_used"`
+	FilesReferenced  []string   `json:"files_referenced"`
+}
+
+var (
+	modelAnnotations   = regexp.MustCompile(`<!--\s*ask-model:\s*(\S+)\s*-->`)
+	interruptedMarkers = regexp.MustCompile(`\[(?:Interrupted after \d+ tokens|Recovered from partial file)\]`)
+)
+
+// Run executes the meta command
+func (c *MetaCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	info, err := os.Stat(c.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", c.Session)
+		}
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+
+	data, err := os.ReadFile(c.Session)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+	content := string(data)
+
+	turns, err := parseTurns(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	path, err := filepath.Abs(c.Session)
+	if err != nil {
+		path = c.Session
+	}
+
+	meta := sessionMeta{
+		SessionPath:     path,
+		LastModified:    info.ModTime().UTC(),
+		TurnCount:       len(turns),
+		ModelsUsed:      []string{},
+		FilesReferenced: []string{},
+	}
+
+	seen := make(map[string]bool)
+	for _, turn := range turns {
+		meta.TotalChars += utf8.RuneCountInString(turn.Content)
+
+		if turn.Role == "AI" {
+			meta.AITurns++
+			if interruptedMarkers.MatchString(turn.Content) {
+				meta.InterruptedTurns++
+			}
+			continue
+		}
+
+		meta.HumanTurns++
+		for _, match := range expand.ReferencePattern.FindAllStringSubmatch(turn.Content, -1) {
+			if !seen[match[1]] && !expand.IsFootnote(match[1]) {
+				seen[match[1]] = true
+				meta.FilesReferenced = append(meta.FilesReferenced, match[1])
+			}
+		}
+	}
+
+	models := make(map[string]bool)
+	for _, match := range modelAnnotations.FindAllStringSubmatch(content, -1) {
+		if !models[match[1]] {
+			models[match[1]] = true
+			meta.ModelsUsed = append(meta.ModelsUsed, match[1])
+		}
+	}
+
+	for _, expansion := range session.ParseExpansionMetadata(content) {
+		if at := expansion.At.UTC(); !expansion.At.IsZero() && (meta.Created == nil || at.Before(*meta.Created)) {
+			meta.Created = &at
+		}
+	}
+
+	out, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	fmt.Println(string(out))
+	return nil
+}
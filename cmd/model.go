@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rana/ask/internal/bedrock"
+	"github.com/rana/ask/internal/config"
+)
+
+// ModelInfoCmd shows what ask knows about a model
+type ModelInfoCmd struct {
+	Model string `help:"Model type, type@version or full ID (default: the configured model)"`
+}
+
+// Run executes the model-info command
+func (c *ModelInfoCmd) Run(cmdCtx *Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	spec := c.Model
+	if spec == "" {
+		spec = cfg.Model
+	}
+
+	modelID, err := config.SelectModel(spec)
+	if err != nil {
+		return fmt.Errorf("failed to resolve model '%s': %w", spec, err)
+	}
+
+	fmt.Printf("Model ID:       %s\n", modelID)
+
+	// The model list is cached for a day, so this rarely calls AWS
+	if models, err := config.GetModels(); err == nil {
+		for _, m := range models {
+			if m.ID != modelID {
+				continue
+			}
+			fmt.Printf("Name:           %s\n", m.Name)
+			fmt.Printf("Type:           %s\n", m.Type)
+			fmt.Printf("Version:        %s\n", m.Version)
+			fmt.Printf("Date:           %s\n", m.Date)
+			break
+		}
+	}
+
+	caps := bedrock.Capabilities(modelID)
+	fmt.Printf("Thinking:       %v\n", caps.SupportsThinking)
+	if caps.Supports1MContext {
+		fmt.Printf("Context Window: 1M tokens (200K unless context is 1m)\n")
+	} else {
+		fmt.Printf("Context Window: 200K tokens\n")
+	}
+
+	if cfg.ProvisionedARN != "" {
+		fmt.Printf("Provisioned:    %s (used instead of a profile)\n", cfg.ProvisionedARN)
+	} else if arn, ok := bedrock.CachedProfileARN(modelID); ok {
+		fmt.Printf("Profile ARN:    %s\n", arn)
+	} else {
+		fmt.Printf("Profile ARN:    (not cached; discovered on first use)\n")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// NextCmd opens the next human turn after an AI response
+type NextCmd struct {
+	Session string `help:"Session file path (default: session.md)"`
+	Open    bool   `help:"Open the session in $EDITOR afterwards"`
+}
+
+// Run executes the next command
+func (c *NextCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	content, err := os.ReadFile(c.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", c.Session)
+		}
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+
+	turns, err := parseTurns(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+	if len(turns) == 0 {
+		return fmt.Errorf("no turns in %s", c.Session)
+	}
+
+	last := turns[len(turns)-1]
+	switch {
+	case last.Role == "Human" && strings.TrimSpace(last.Content) == "":
+		cmdCtx.Printf("Turn %d is already open in %s\n", last.Number, c.Session)
+	case last.Role == "Human":
+		return fmt.Errorf("turn %d is a human turn awaiting a response. Run 'ask chat' first", last.Number)
+	default:
+		next := "\n\n" + sessionFormat().Header(last.Number+1, "Human") + "\n\n"
+		updated := strings.TrimRight(string(content), "\n") + next
+		if err := os.WriteFile(c.Session, []byte(updated), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", c.Session, err)
+		}
+		cmdCtx.Printf("Added turn %d to %s\n", last.Number+1, c.Session)
+	}
+
+	if c.Open {
+		return openInEditor(c.Session)
+	}
+	return nil
+}
+
+// openInEditor runs $EDITOR on path, falling back to vi
+func openInEditor(path string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// $EDITOR may carry arguments, e.g. "code --wait"
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/rana/ask/internal/session"
+)
+
+// PasteCmd starts a session from the clipboard and runs it
+type PasteCmd struct {
+	Question string `help:"Question to ask about the clipboard content"`
+}
+
+// Run executes the paste command
+func (c *PasteCmd) Run(cmdCtx *Context) error {
+	path := cmdCtx.SessionPath("")
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists. Delete it to start fresh", path)
+	}
+
+	clip, err := readClipboard()
+	if err != nil {
+		return err
+	}
+
+	clip = strings.TrimSpace(clip)
+	if clip == "" {
+		return fmt.Errorf("clipboard is empty")
+	}
+
+	var b strings.Builder
+	b.WriteString(sessionFormat().Header(1, "Human") + "\n\n")
+	b.WriteString(formatClipboard(clip))
+	if c.Question != "" {
+		b.WriteString("\n\n")
+		b.WriteString(c.Question)
+	}
+	b.WriteString("\n")
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := session.WriteAtomic(path, []byte(b.String())); err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	cmdCtx.Printf("Created %s from clipboard\n", path)
+
+	return (&ChatCmd{}).Run(cmdCtx)
+}
+
+// readClipboard runs the first available clipboard tool for the platform
+func readClipboard() (string, error) {
+	var tools [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		tools = [][]string{{"pbpaste"}}
+	case "windows":
+		tools = [][]string{{"powershell", "-NoProfile", "-Command", "Get-Clipboard"}}
+	default:
+		tools = [][]string{
+			{"wl-paste", "--no-newline"},
+			{"xclip", "-selection", "clipboard", "-o"},
+			{"xsel", "--clipboard", "--output"},
+		}
+	}
+
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool[0]); err != nil {
+			continue
+		}
+		output, err := exec.Command(tool[0], tool[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to read clipboard with %s: %w", tool[0], err)
+		}
+		return string(output), nil
+	}
+
+	return "", fmt.Errorf("no clipboard tool found (install pbpaste, wl-paste, xclip or xsel)")
+}
+
+// formatClipboard fences code in a block tagged with its detected language.
+// URLs and prose are left as-is.
+func formatClipboard(clip string) string {
+	if isURL(clip) {
+		return clip
+	}
+
+	lang, isCode := detectLanguage(clip)
+	if !isCode {
+		return clip
+	}
+
+	fence := "```"
+	for strings.Contains(clip, fence) {
+		fence += "`"
+	}
+	return fmt.Sprintf("%s%s\n%s\n%s", fence, lang, clip, fence)
+}
+
+// isURL reports whether text is a single http(s) URL
+func isURL(text string) bool {
+	return (strings.HasPrefix(text, "http://") || strings.HasPrefix(text, "https://")) &&
+		!strings.ContainsAny(text, " \n\t")
+}
+
+// detectLanguage guesses the language of a snippet from its first line
+func detectLanguage(text string) (string, bool) {
+	markers := []struct {
+		prefix string
+		lang   string
+	}{
+		{"package ", "go"},
+		{"func ", "go"},
+		{"#!/bin/bash", "bash"},
+		{"#!/bin/sh", "sh"},
+		{"#!/usr/bin/env python", "python"},
+		{"def ", "python"},
+		{"from ", "python"},
+		{"fn ", "rust"},
+		{"use ", "rust"},
+		{"#include", "c"},
+		{"function ", "javascript"},
+		{"const ", "javascript"},
+		{"import ", ""},
+		{"SELECT ", "sql"},
+		{"<?php", "php"},
+		{"<", "html"},
+	}
+
+	// Only the first line decides, so prose mentioning "from" isn't code
+	first, _, _ := strings.Cut(text, "\n")
+	for _, m := range markers {
+		if strings.HasPrefix(strings.TrimSpace(first), m.prefix) {
+			return m.lang, true
+		}
+	}
+
+	// Braces and semicolons at line ends are a good sign of code
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasSuffix(line, "{") || strings.HasSuffix(line, ";") || strings.HasSuffix(line, "}") {
+			return "", true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rana/ask/internal/expand"
+	"github.com/rana/ask/internal/session"
+)
+
+// PreviewCmd prints a human turn as it would be sent, without calling the API
+type PreviewCmd struct {
+	Session  string `help:"Session file path (default: session.md)"`
+	Turn     int    `help:"Human turn to show (default: the last one)"`
+	Expanded bool   `help:"Expand [[references]] as chat would"`
+	Tokens   bool   `help:"Append the estimated token count"`
+	SizeOnly bool   `help:"List referenced files with sizes from disk, without reading them"`
+}
+
+// Run executes the preview command
+func (c *PreviewCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	content, err := os.ReadFile(c.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", c.Session)
+		}
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+
+	turns, err := parseTurns(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	var turn *session.Turn
+	for i := len(turns) - 1; i >= 0; i-- {
+		if turns[i].Role == "Human" && (c.Turn == 0 || turns[i].Number == c.Turn) {
+			turn = &turns[i]
+			break
+		}
+	}
+	if turn == nil {
+		if c.Turn != 0 {
+			return fmt.Errorf("no human turn %d in %s", c.Turn, c.Session)
+		}
+		return fmt.Errorf("no human turn found in %s", c.Session)
+	}
+
+	if c.SizeOnly {
+		return c.printSizes(turn)
+	}
+
+	text := turn.Content
+	if c.Expanded {
+		if text, _, err = expand.ExpandReferences(text, turn.Number, expand.Options{Quiet: true}); err != nil {
+			return fmt.Errorf("failed to expand references in turn %d: %w", turn.Number, err)
+		}
+	}
+
+	fmt.Println(text)
+	if c.Tokens {
+		fmt.Printf("\n~%d tokens (estimate)\n", len(text)/4) // Rough approximation
+	}
+	return nil
+}
+
+// printSizes lists the files turn's references expand with their sizes and
+// token estimates, without reading them through the filter pipeline
+func (c *PreviewCmd) printSizes(turn *session.Turn) error {
+	sizes, err := expand.ReferenceSizes(turn.Content, expand.Options{})
+	if err != nil {
+		return fmt.Errorf("failed to size references in turn %d: %w", turn.Number, err)
+	}
+	if len(sizes) == 0 {
+		fmt.Printf("No file references in turn %d\n", turn.Number)
+		return nil
+	}
+
+	var bytes int64
+	tokens := 0
+	for _, size := range sizes {
+		fmt.Printf("%10s  %9s tokens  %s\n", formatSize(size.Bytes), "~"+formatThousands(size.Tokens), size.File)
+		bytes += size.Bytes
+		tokens += size.Tokens
+	}
+	fmt.Printf("\n%d files, %s, ~%s tokens (estimate, before filtering)\n",
+		len(sizes), formatSize(bytes), formatThousands(tokens))
+	return nil
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rana/ask/internal/bedrock"
+	"github.com/rana/ask/internal/config"
+)
+
+// QuotaCmd shows the service quotas that limit requests to the model
+type QuotaCmd struct {
+	ServiceCode string `default:"bedrock" help:"Service Quotas service code; other services list every quota"`
+}
+
+// Run executes the quota command
+func (c *QuotaCmd) Run(cmdCtx *Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	modelType := config.ModelType(cfg.Model)
+
+	ctx, cancel := context.WithTimeout(cmdCtx.Context, 30*time.Second)
+	defer cancel()
+
+	quotas, err := bedrock.ListServiceQuotas(ctx, c.ServiceCode)
+	if err != nil {
+		if c.ServiceCode != "bedrock" {
+			return fmt.Errorf("failed to list quotas for %s: %w", c.ServiceCode, err)
+		}
+		fmt.Printf("Quotas unavailable from the Service Quotas API: %v\n\n", err)
+		printDefaultQuotas(modelType)
+		return nil
+	}
+
+	// Bedrock has quotas for every model; keep those of the configured type
+	if c.ServiceCode == "bedrock" {
+		var matching []bedrock.Quota
+		for _, q := range quotas {
+			name := strings.ToLower(q.Name)
+			if strings.Contains(name, "claude") && (modelType == "" || strings.Contains(name, modelType)) {
+				matching = append(matching, q)
+			}
+		}
+		if len(matching) == 0 {
+			fmt.Printf("No Claude %s quotas reported for this account\n\n", modelType)
+			printDefaultQuotas(modelType)
+			return nil
+		}
+		quotas = matching
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "QUOTA\tVALUE\tADJUSTABLE")
+	for _, q := range quotas {
+		adjustable := "no"
+		if q.Adjustable {
+			adjustable = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", q.Name, formatThousands(int(q.Value)), adjustable)
+	}
+	return w.Flush()
+}
+
+// printDefaultQuotas prints the typical quotas for modelType, or for every
+// type if it is unknown
+func printDefaultQuotas(modelType string) {
+	types := []string{"opus", "sonnet", "haiku"}
+	if _, ok := config.DefaultQuotas[modelType]; ok {
+		types = []string{modelType}
+	}
+
+	fmt.Println("Typical on-demand defaults (actual quotas vary by region and account):")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tREQUESTS/MIN\tTOKENS/MIN")
+	for _, t := range types {
+		q := config.DefaultQuotas[t]
+		fmt.Fprintf(w, "%s\t%s\t%s\n", t, formatThousands(q.RequestsPerMinute), formatThousands(q.TokensPerMinute))
+	}
+	w.Flush()
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rana/ask/internal/session"
+)
+
+// RenumberCmd makes turn numbers sequential again after manual edits
+type RenumberCmd struct {
+	Session string `help:"Session file path (default: session.md)"`
+}
+
+// Run executes the renumber command
+func (c *RenumberCmd) Run(cmdCtx *Context) error {
+	path := cmdCtx.SessionPath(c.Session)
+
+	moves, err := session.RenumberTurns(path)
+	if err != nil {
+		return err
+	}
+
+	changed := 0
+	for _, m := range moves {
+		if m.Old != m.New {
+			fmt.Printf("Old [%d] %s → New [%d] %s\n", m.Old, m.Role, m.New, m.Role)
+			changed++
+		}
+	}
+
+	if changed == 0 {
+		fmt.Printf("Turns 1–%d are already in sequence\n", len(moves))
+		return nil
+	}
+	fmt.Printf("\nRenumbered %d of %d turns in %s\n", changed, len(moves), path)
+	return nil
+}
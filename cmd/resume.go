@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/rana/ask/internal/session"
+)
+
+// ResumeCmd recovers a response that was cut off before it was finalized
+type ResumeCmd struct {
+	Session string `help:"Session file path (default: session.md)"`
+}
+
+// Run executes the resume command
+func (c *ResumeCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	turnNumber, err := session.RecoverPartial(c.Session)
+	if err != nil {
+		return err
+	}
+
+	cmdCtx.Printf("Recovered turn %d in %s from %s\n", turnNumber, c.Session, session.PartialPath(c.Session))
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+)
+
+// SearchCmd finds sessions in the session index
+type SearchCmd struct {
+	Terms []string `arg:"" optional:"" help:"Words that must all appear in a session's path, tags, models or first question"`
+	Tag   string   `help:"Only sessions with this tag"`
+}
+
+// Run executes the search command
+func (c *SearchCmd) Run(cmdCtx *Context) error {
+	if len(c.Terms) == 0 && c.Tag == "" {
+		return fmt.Errorf("give words to search for, --tag, or both")
+	}
+	query := strings.Join(c.Terms, " ")
+	if c.Tag != "" {
+		tag, err := session.NormalizeTag(c.Tag)
+		if err != nil {
+			return err
+		}
+		c.Tag = tag
+		query = strings.TrimSpace(query + " tag:" + tag)
+	}
+
+	index, err := session.LoadSessionIndex(config.SessionIndexPath())
+	if err != nil {
+		return err
+	}
+	if len(index.Sessions) == 0 {
+		return fmt.Errorf("session index is empty. Run: ask index rebuild")
+	}
+
+	results := index.Search(c.Terms, c.Tag)
+	if len(results) == 0 {
+		return fmt.Errorf("no sessions match '%s'", query)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "MODIFIED\tTURNS\tTAGS\tSESSION\tFIRST WORDS")
+	for _, e := range results {
+		tags := strings.Join(e.Tags, ",")
+		if tags == "" {
+			tags = "-"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n",
+			e.LastModified.Local().Format("2006-01-02 15:04"), e.TurnCount, tags, e.Path, e.FirstHumanWords)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+)
+
+// SetModelCmd writes an ask-model annotation into the session
+type SetModelCmd struct {
+	Model   string `arg:"" help:"Model type (opus/sonnet/haiku) or full model ID"`
+	Session string `help:"Session file path (default: session.md)"`
+}
+
+// Run executes the set-model command
+func (c *SetModelCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	content, err := os.ReadFile(c.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", c.Session)
+		}
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+
+	// Validate that the model exists or can be resolved
+	resolved, err := config.SelectModel(c.Model)
+	if err != nil {
+		return fmt.Errorf("invalid model '%s': %w", c.Model, err)
+	}
+
+	updated := session.SetModel(string(content), c.Model)
+	if err := session.WriteAtomic(c.Session, []byte(updated)); err != nil {
+		return fmt.Errorf("failed to update %s: %w", c.Session, err)
+	}
+
+	fmt.Printf("Model for %s set to: %s\n", c.Session, c.Model)
+	if resolved != c.Model {
+		fmt.Printf("Resolves to:  %s\n", resolved)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rana/ask/internal/bedrock"
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+)
+
+// StatsCmd shows the expansion metadata recorded in each Human turn
+type StatsCmd struct {
+	Session     string `help:"Session file path (default: session.md)"`
+	WithActuals bool   `help:"Also count each expanded turn with the Bedrock token API"`
+}
+
+// Run executes the stats command
+func (c *StatsCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	content, err := os.ReadFile(c.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", c.Session)
+		}
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+
+	turns, err := parseTurns(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	if err := c.printExpansions(cmdCtx, string(content), turns); err != nil {
+		return err
+	}
+	printResponseTimes(turns)
+	return nil
+}
+
+// printExpansions lists the expansion metadata of each Human turn
+func (c *StatsCmd) printExpansions(cmdCtx *Context, content string, turns []session.Turn) error {
+	metas := session.ParseExpansionMetadata(content)
+	if len(metas) == 0 {
+		fmt.Printf("No expanded turns in %s\n", c.Session)
+		return nil
+	}
+
+	byNumber := make(map[int]session.Turn)
+	for _, turn := range turns {
+		if turn.Role == "Human" {
+			byNumber[turn.Number] = turn
+		}
+	}
+
+	var modelID string
+	if c.WithActuals {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if meta := session.ReadMetadata(content); meta.Model != "" {
+			cfg.Model = meta.Model
+		}
+		if modelID, err = cfg.ResolveModel(); err != nil {
+			return fmt.Errorf("failed to resolve model: %w", err)
+		}
+	}
+
+	var numbers []int
+	for n := range metas {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	totalFiles, totalTokens := 0, 0
+	for _, n := range numbers {
+		meta := metas[n]
+		totalFiles += meta.Files
+		totalTokens += meta.Tokens
+
+		line := fmt.Sprintf("Turn %d: %d files, ~%d tokens", n, meta.Files, meta.Tokens)
+		if !meta.At.IsZero() {
+			line += fmt.Sprintf(" (%s)", meta.At.Local().Format("Jan 2 15:04"))
+		}
+
+		if c.WithActuals {
+			actual, err := bedrock.CountTokensForTurns(cmdCtx.Context, []session.Turn{byNumber[n]}, modelID)
+			if err != nil {
+				line += ", actual unavailable"
+				cmdCtx.Printf("Warning: turn %d: %v\n", n, err)
+			} else {
+				line += fmt.Sprintf(", %d actual", actual)
+			}
+		}
+
+		fmt.Println(line)
+	}
+
+	fmt.Printf("Total: %d files, ~%d tokens\n", totalFiles, totalTokens)
+	return nil
+}
+
+// printResponseTimes summarizes durations recorded with timing enabled
+func printResponseTimes(turns []session.Turn) {
+	count, total := 0, 0
+	for _, turn := range turns {
+		if turn.Role == "AI" && turn.DurationSec > 0 {
+			count++
+			total += turn.DurationSec
+		}
+	}
+
+	if count == 0 {
+		return
+	}
+	fmt.Printf("Average response time: %ds over %d responses\n", total/count, count)
+}
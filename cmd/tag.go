@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+)
+
+// TagCmd manages tags on sessions and turns
+type TagCmd struct {
+	Add    TagAddCmd    `cmd:"" help:"Tag the session, or one turn with --turn"`
+	Remove TagRemoveCmd `cmd:"" help:"Remove a tag from the session or a turn"`
+	List   TagListCmd   `cmd:"" help:"List tags used across sessions, or where one tag is used"`
+}
+
+// TagAddCmd adds a tag
+type TagAddCmd struct {
+	Tag     string `arg:"" help:"Tag to add, e.g. code-review"`
+	Turn    int    `help:"Tag this turn instead of the whole session"`
+	Session string `help:"Session file path (default: session.md)"`
+}
+
+// Run executes the tag add command
+func (c *TagAddCmd) Run(cmdCtx *Context) error {
+	return updateTag(cmdCtx, c.Session, c.Tag, c.Turn, true)
+}
+
+// TagRemoveCmd removes a tag
+type TagRemoveCmd struct {
+	Tag     string `arg:"" help:"Tag to remove"`
+	Turn    int    `help:"Remove the tag from this turn instead of the whole session"`
+	Session string `help:"Session file path (default: session.md)"`
+}
+
+// Run executes the tag remove command
+func (c *TagRemoveCmd) Run(cmdCtx *Context) error {
+	return updateTag(cmdCtx, c.Session, c.Tag, c.Turn, false)
+}
+
+// updateTag adds or removes tag in the session's front matter and the index
+func updateTag(cmdCtx *Context, path, tag string, turn int, add bool) error {
+	tag, err := session.NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+
+	path = cmdCtx.SessionPath(path)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", path)
+		}
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if turn != 0 && session.LastTurnNumber(string(content)) < turn {
+		return fmt.Errorf("no turn %d in %s", turn, path)
+	}
+
+	tags := session.ReadTags(string(content), turn)
+	var updated []string
+	found := false
+	for _, t := range tags {
+		if t == tag {
+			found = true
+			if !add {
+				continue
+			}
+		}
+		updated = append(updated, t)
+	}
+	if add && !found {
+		updated = append(updated, tag)
+	}
+	if !add && !found {
+		return fmt.Errorf("%s is not tagged '%s'", tagTarget(path, turn), tag)
+	}
+
+	if add != found {
+		content = []byte(session.SetTags(string(content), turn, updated))
+		if err := session.WriteAtomic(path, content); err != nil {
+			return fmt.Errorf("failed to update %s: %w", path, err)
+		}
+	}
+
+	// The index keys sessions by absolute path so tags can be listed from anywhere
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	entry := session.TagEntry{Tag: tag, Session: abs, Turn: turn}
+	err = session.UpdateTagIndex(config.TagIndexPath(), func(index *session.TagIndex) error {
+		if add {
+			index.Add(entry)
+		} else {
+			index.Remove(entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// ask search --tag filters on the session index, so it must see the change now
+	if err := updateSessionIndex(path, ""); err != nil {
+		return fmt.Errorf("failed to update session index: %w", err)
+	}
+
+	if add {
+		fmt.Printf("Tagged %s: %s\n", tagTarget(path, turn), tag)
+	} else {
+		fmt.Printf("Removed '%s' from %s\n", tag, tagTarget(path, turn))
+	}
+	return nil
+}
+
+func tagTarget(path string, turn int) string {
+	if turn == 0 {
+		return path
+	}
+	return fmt.Sprintf("turn %d of %s", turn, path)
+}
+
+// TagListCmd lists tags from ~/.ask/index.toml
+type TagListCmd struct {
+	Tag string `arg:"" optional:"" help:"Show the sessions and turns with this tag"`
+}
+
+// Run executes the tag list command
+func (c *TagListCmd) Run(cmdCtx *Context) error {
+	index, err := session.LoadTagIndex(config.TagIndexPath())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	if c.Tag == "" {
+		counts := index.Counts()
+		if len(counts) == 0 {
+			fmt.Println("No tags yet. Add one with: ask tag add <tag>")
+			return nil
+		}
+		tags := make([]string, 0, len(counts))
+		for tag := range counts {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			fmt.Fprintf(w, "%s\t%d\n", tag, counts[tag])
+		}
+		return nil
+	}
+
+	tag, err := session.NormalizeTag(c.Tag)
+	if err != nil {
+		return err
+	}
+	entries := index.Find(tag)
+	if len(entries) == 0 {
+		return fmt.Errorf("no sessions tagged '%s'", tag)
+	}
+	for _, e := range entries {
+		turn := "session"
+		if e.Turn != 0 {
+			turn = fmt.Sprintf("turn %d", e.Turn)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", e.Session, turn)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rana/ask/internal/bedrock"
+	"github.com/rana/ask/internal/session"
+)
+
+// titleMaxTokens keeps the title request short and cheap
+const titleMaxTokens = 20
+
+// TitleCmd infers a short title from the first human turn
+type TitleCmd struct {
+	Session string `help:"Session file path (default: session.md)"`
+	Set     bool   `help:"Write the title into the session as <!-- title: ... -->"`
+}
+
+// Run executes the title command
+func (c *TitleCmd) Run(cmdCtx *Context) error {
+	c.Session = cmdCtx.SessionPath(c.Session)
+
+	content, err := os.ReadFile(c.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", c.Session)
+		}
+		return fmt.Errorf("failed to read %s: %w", c.Session, err)
+	}
+
+	turns, err := parseTurns(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	var question string
+	for _, turn := range turns {
+		if turn.Role == "Human" {
+			question = turn.Content
+			break
+		}
+	}
+	if question == "" {
+		return fmt.Errorf("the first human turn in %s is empty", c.Session)
+	}
+
+	// Sent on its own, so nothing is written to the session
+	response, err := bedrock.SendToClaudeWithMaxTokens("Summarize this in 5 words or fewer:\n\n"+question, titleMaxTokens)
+	if err != nil {
+		return fmt.Errorf("failed to generate title: %w", err)
+	}
+	title := strings.Trim(strings.TrimSpace(response), `"'.`)
+
+	fmt.Println(title)
+
+	if c.Set {
+		updated := session.SetTitle(string(content), title)
+		if err := session.WriteAtomic(c.Session, []byte(updated)); err != nil {
+			return fmt.Errorf("failed to update %s: %w", c.Session, err)
+		}
+		cmdCtx.Printf("Title written to %s\n", c.Session)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rana/ask/internal/analytics"
+	"github.com/rana/ask/internal/config"
+)
+
+// UsageCmd summarizes recorded token usage and estimated cost per model
+type UsageCmd struct {
+	Since string `help:"Only include usage within this long ago (e.g., 7d, 12h)"`
+	Until string `help:"Only include usage up to and including this date (YYYY-MM-DD)"`
+	CSV   bool   `name:"csv" help:"Print CSV for spreadsheet import"`
+}
+
+// Run executes the usage command
+func (c *UsageCmd) Run(cmdCtx *Context) error {
+	var since, until time.Time
+	if c.Since != "" {
+		age, err := config.ParseAge(c.Since)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		since = time.Now().Add(-age)
+	}
+	if c.Until != "" {
+		date, err := time.ParseInLocation("2006-01-02", c.Until, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --until '%s', expected YYYY-MM-DD", c.Until)
+		}
+		until = date.AddDate(0, 0, 1) // Through the end of that day
+	}
+
+	path := config.UsagePath()
+	entries, skipped, err := analytics.Load(path)
+	if err != nil {
+		return err
+	}
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: skipped %d malformed line(s) in %s\n", skipped, path)
+	}
+
+	usage := analytics.GroupByModel(entries, since, until)
+	if c.CSV {
+		return writeUsageCSV(usage)
+	}
+
+	if len(usage) == 0 {
+		fmt.Println("No usage recorded for this period")
+		return nil
+	}
+
+	fmt.Printf("%-45s %8s %12s %12s %10s %7s\n", "MODEL", "REQUESTS", "INPUT", "OUTPUT", "COST", "SHARE")
+	var requests, input, output int
+	var cost float64
+	for _, u := range usage {
+		fmt.Printf("%-45s %8d %12s %12s %10s %6.1f%%\n",
+			u.Model, u.Requests, formatThousands(u.InputTokens), formatThousands(u.OutputTokens), formatCost(u), u.Share)
+		requests += u.Requests
+		input += u.InputTokens
+		output += u.OutputTokens
+		cost += u.Cost
+	}
+	fmt.Printf("%-45s %8d %12s %12s %10s\n", "TOTAL", requests, formatThousands(input), formatThousands(output), fmt.Sprintf("$%.2f", cost))
+
+	return nil
+}
+
+// formatCost renders a model's cost, or "?" when its price is unknown
+func formatCost(u analytics.ModelUsage) string {
+	if !u.Priced {
+		return "?"
+	}
+	return fmt.Sprintf("$%.2f", u.Cost)
+}
+
+// writeUsageCSV prints usage as CSV with a header row
+func writeUsageCSV(usage []analytics.ModelUsage) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"model", "requests", "input_tokens", "output_tokens", "cost_usd", "share_percent"})
+	for _, u := range usage {
+		cost := ""
+		if u.Priced {
+			cost = strconv.FormatFloat(u.Cost, 'f', 4, 64)
+		}
+		w.Write([]string{
+			u.Model,
+			strconv.Itoa(u.Requests),
+			strconv.Itoa(u.InputTokens),
+			strconv.Itoa(u.OutputTokens),
+			cost,
+			strconv.FormatFloat(u.Share, 'f', 1, 64),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
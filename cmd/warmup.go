@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rana/ask/internal/bedrock"
+	"github.com/rana/ask/internal/verbose"
+)
+
+// warmupModels are the model types warmed up by --models=all
+var warmupModels = []string{"opus", "sonnet", "haiku"}
+
+// WarmupCmd caches inference profiles and checks each model can be invoked
+type WarmupCmd struct {
+	Models string `default:"all" help:"Comma-separated models to warm up, or 'all' for opus, sonnet and haiku"`
+}
+
+// Run executes the warmup command
+func (c *WarmupCmd) Run(cmdCtx *Context) error {
+	models := warmupModels
+	if c.Models != "all" {
+		models = nil
+		for _, model := range strings.Split(c.Models, ",") {
+			if model = strings.TrimSpace(model); model != "" {
+				models = append(models, model)
+			}
+		}
+		if len(models) == 0 {
+			return fmt.Errorf("no models given. Use --models=all or e.g. --models=opus,haiku")
+		}
+	}
+
+	var results []bedrock.WarmupResult
+	var failures []string
+	failed := make(map[string]bool)
+	for _, model := range models {
+		cmdCtx.Logf(verbose.Verbose, "Warming up %s...\n", model)
+
+		ctx, cancel := context.WithTimeout(cmdCtx.Context, 60*time.Second)
+		result, err := bedrock.Warmup(ctx, model)
+		cancel()
+
+		results = append(results, result)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", model, err))
+			failed[model] = true
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tPROFILE ARN\tDISCOVERY\tTEST\tSTATUS")
+	for _, r := range results {
+		profile, discovery, invocation := r.ProfileARN, "-", "-"
+		if profile == "" {
+			profile = "-"
+		} else {
+			discovery = r.Discovery.Round(time.Millisecond).String()
+			if r.Invocation > 0 {
+				invocation = r.Invocation.Round(time.Millisecond).String()
+			}
+		}
+		status := "ok"
+		if failed[r.Model] {
+			status = "failed"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Model, profile, discovery, invocation, status)
+	}
+	w.Flush()
+
+	if len(failures) > 0 {
+		fmt.Println()
+		for _, failure := range failures {
+			fmt.Println(failure)
+		}
+		return fmt.Errorf("%d of %d models failed to warm up", len(failures), len(models))
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rana/ask/internal/session"
+)
+
+const (
+	watchPollInterval = 250 * time.Millisecond
+	watchDebounce     = 500 * time.Millisecond
+)
+
+// WatchCmd runs chat each time the session is saved with a new question
+type WatchCmd struct{}
+
+// watcher debounces saves and runs chat at most once at a time
+type watcher struct {
+	cmdCtx  *Context
+	path    string
+	running atomic.Bool
+	pending atomic.Bool // At most one run waits for the current one
+	lastRun atomic.Int64
+	stateMu sync.Mutex // Held while running and pending change together
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+// Run executes the watch command
+func (c *WatchCmd) Run(cmdCtx *Context) error {
+	w := &watcher{cmdCtx: cmdCtx, path: cmdCtx.SessionPath("")}
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s found. Run 'ask init' to start", w.path)
+		}
+		return fmt.Errorf("failed to read %s: %w", w.path, err)
+	}
+
+	cmdCtx.Printf("Watching %s, save a new human turn to send it [ctrl+c to stop]\n", w.path)
+
+	// Poll rather than depend on platform file events
+	lastMod := info.ModTime()
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cmdCtx.Done():
+			return nil
+		case <-ticker.C:
+			info, err := os.Stat(w.path)
+			if err != nil || info.ModTime().Equal(lastMod) {
+				continue
+			}
+			lastMod = info.ModTime()
+			w.saved()
+		}
+	}
+}
+
+// saved restarts the debounce timer, so a burst of saves triggers one run
+func (w *watcher) saved() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(watchDebounce, w.trigger)
+}
+
+// trigger starts a run, or queues one if a run is in progress
+func (w *watcher) trigger() {
+	if !w.ready() {
+		return // e.g. the save was chat writing its own response
+	}
+
+	w.stateMu.Lock()
+	defer w.stateMu.Unlock()
+
+	if w.running.Load() {
+		if !w.pending.Swap(true) {
+			w.cmdCtx.Printf("[Queued]\n")
+		}
+		return
+	}
+
+	w.running.Store(true)
+	go w.run()
+}
+
+// run runs chat, then any run queued meanwhile. trigger sets running
+// before starting it.
+func (w *watcher) run() {
+	for {
+		if turns := w.turns(); len(turns) > 0 {
+			w.lastRun.Store(int64(turns[len(turns)-1].Number))
+		}
+
+		chat := &ChatCmd{}
+		if err := chat.Run(w.cmdCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "ask: error: %v\n", err)
+		}
+
+		// Checked under stateMu, so a trigger either queues before this
+		// or sees running cleared and starts a new run itself
+		w.stateMu.Lock()
+		if !w.pending.Swap(false) || !w.ready() {
+			w.running.Store(false)
+			w.stateMu.Unlock()
+			return
+		}
+		w.stateMu.Unlock()
+	}
+}
+
+// ready reports whether the session ends in a human turn that has not been sent
+func (w *watcher) ready() bool {
+	turns := w.turns()
+	if len(turns) == 0 {
+		return false
+	}
+
+	last := turns[len(turns)-1]
+	if last.Role != "Human" || strings.TrimSpace(last.Content) == "" {
+		return false
+	}
+
+	// The run in progress already covers its own turn
+	return !w.running.Load() || int64(last.Number) > w.lastRun.Load()
+}
+
+func (w *watcher) turns() []session.Turn {
+	content, err := os.ReadFile(w.path)
+	if err != nil {
+		return nil
+	}
+	turns, _ := parseTurns(string(content))
+	return turns
+}
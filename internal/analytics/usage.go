@@ -0,0 +1,167 @@
+package analytics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rana/ask/internal/config"
+)
+
+// Entry is one line of ~/.ask/usage.jsonl, recorded per response
+type Entry struct {
+	Time         time.Time `json:"time"`
+	Model        string    `json:"model"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+}
+
+// ModelUsage totals the entries of one model
+type ModelUsage struct {
+	Model        string
+	Requests     int
+	InputTokens  int
+	OutputTokens int
+	Cost         float64 // USD; zero when the model has no known price
+	Priced       bool
+	Share        float64 // Percentage of the total cost
+}
+
+// Price is the USD cost per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// pricing is matched in order against model IDs, so specific versions come
+// before their family
+var pricing = []struct {
+	match string
+	price Price
+}{
+	{"opus-4-5", Price{Input: 5, Output: 25}},
+	{"opus", Price{Input: 15, Output: 75}},
+	{"sonnet", Price{Input: 3, Output: 15}},
+	{"haiku-4-5", Price{Input: 1, Output: 5}},
+	{"3-5-haiku", Price{Input: 0.8, Output: 4}},
+	{"haiku", Price{Input: 0.25, Output: 1.25}},
+}
+
+// PriceFor returns the price of a model ID and whether it is known
+func PriceFor(model string) (Price, bool) {
+	for _, p := range pricing {
+		if strings.Contains(model, p.match) {
+			return p.price, true
+		}
+	}
+	return Price{}, false
+}
+
+// Record appends a response's token usage to the usage log
+func Record(model string, inputTokens, outputTokens int) error {
+	path := config.UsagePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	line, err := json.Marshal(Entry{
+		Time:         time.Now().UTC(),
+		Model:        model,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// Load reads the usage log, skipping lines that don't parse. A missing log
+// is empty rather than an error.
+func Load(path string) (entries []Entry, skipped int, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Model == "" {
+			skipped++
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return entries, skipped, nil
+}
+
+// GroupByModel totals entries from since up to until, most expensive first.
+// Zero times leave that end open.
+func GroupByModel(entries []Entry, since, until time.Time) []ModelUsage {
+	byModel := make(map[string]*ModelUsage)
+	for _, entry := range entries {
+		if (!since.IsZero() && entry.Time.Before(since)) || (!until.IsZero() && !entry.Time.Before(until)) {
+			continue
+		}
+
+		usage, ok := byModel[entry.Model]
+		if !ok {
+			usage = &ModelUsage{Model: entry.Model}
+			byModel[entry.Model] = usage
+		}
+		usage.Requests++
+		usage.InputTokens += entry.InputTokens
+		usage.OutputTokens += entry.OutputTokens
+	}
+
+	var total float64
+	var result []ModelUsage
+	for _, usage := range byModel {
+		if price, ok := PriceFor(usage.Model); ok {
+			usage.Priced = true
+			usage.Cost = (float64(usage.InputTokens)*price.Input + float64(usage.OutputTokens)*price.Output) / 1_000_000
+			total += usage.Cost
+		}
+		result = append(result, *usage)
+	}
+
+	for i := range result {
+		if total > 0 {
+			result[i].Share = result[i].Cost / total * 100
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		return result[i].Model < result[j].Model
+	})
+	return result
+}
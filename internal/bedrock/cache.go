@@ -3,11 +3,18 @@ package bedrock
 import (
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
+// profileTTL is how long a discovered profile ARN is trusted
+const profileTTL = 30 * 24 * time.Hour
+
+// cacheMu serializes reads and read-modify-write cycles on the profile cache
+var cacheMu sync.Mutex
+
 type ProfileCache struct {
 	Profiles map[string]ProfileEntry `toml:"profiles"`
 }
@@ -42,20 +49,28 @@ func loadProfileCache() (*ProfileCache, error) {
 	return &cache, nil
 }
 
+// saveProfileCache writes the cache to a temporary file and renames it into
+// place, so a process exiting mid-write never leaves a truncated cache
 func saveProfileCache(cache *ProfileCache) error {
 	cacheDir := filepath.Dir(profileCachePath())
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return err
 	}
 
-	file, err := os.Create(profileCachePath())
+	file, err := os.CreateTemp(cacheDir, "profiles-*.toml")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	defer os.Remove(file.Name()) // Fails harmlessly once renamed
 
-	encoder := toml.NewEncoder(file)
-	return encoder.Encode(cache)
+	if err := toml.NewEncoder(file).Encode(cache); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), profileCachePath())
 }
 
 func profileCachePath() string {
@@ -63,13 +78,16 @@ func profileCachePath() string {
 }
 
 func getCachedProfile(profileName string) (string, bool) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
 	cache, err := loadProfileCache()
 	if err != nil {
 		return "", false
 	}
 
 	if entry, ok := cache.Profiles[profileName]; ok {
-		if time.Since(entry.CreatedAt) < 30*24*time.Hour {
+		if time.Since(entry.CreatedAt) < profileTTL {
 			return entry.ARN, true
 		}
 	}
@@ -78,6 +96,9 @@ func getCachedProfile(profileName string) (string, bool) {
 }
 
 func setCachedProfile(profileName, arn, modelID string) error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
 	cache, _ := loadProfileCache()
 
 	cache.Profiles[profileName] = ProfileEntry{
@@ -88,3 +109,15 @@ func setCachedProfile(profileName, arn, modelID string) error {
 
 	return saveProfileCache(cache)
 }
+
+// ClearProfileCache forgets every discovered profile, so each is found again
+// on next use
+func ClearProfileCache() error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if err := os.Remove(profileCachePath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -3,13 +3,12 @@ package bedrock
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/rana/ask/internal/analytics"
 	"github.com/rana/ask/internal/config"
 	"github.com/rana/ask/internal/session"
 )
@@ -23,23 +22,59 @@ func SendToClaude(content string) (string, error) {
 	return SendToClaudeWithHistory(messages)
 }
 
+// SendToClaudeWithMaxTokens sends a single message with a response limit,
+// for short meta-requests. Thinking is not used since its budget wouldn't fit.
+func SendToClaudeWithMaxTokens(content string, maxTokens int) (string, error) {
+	messages := []session.Turn{
+		{Number: 1, Role: "Human", Content: content},
+	}
+	resp, err := sendToClaudeWithRetry("", messages, maxTokens, false)
+	return resp.Text, err
+}
+
 // SendToClaudeWithHistory sends a full conversation history to Claude
 func SendToClaudeWithHistory(turns []session.Turn) (string, error) {
-	return sendToClaudeWithRetry(turns, false)
+	resp, err := sendToClaudeWithRetry("", turns, 0, false)
+	return resp.Text, err
+}
+
+// Response is a complete response and the tokens it used
+type Response struct {
+	Text         string
+	ModelID      string
+	InputTokens  int
+	OutputTokens int
+}
+
+// SendToModel sends a conversation to model instead of the configured one
+// and returns the response with its token usage
+func SendToModel(model string, turns []session.Turn) (Response, error) {
+	return sendToClaudeWithRetry(model, turns, 0, false)
 }
 
-// sendToClaudeWithRetry handles the actual sending with retry logic for stale profiles
-func sendToClaudeWithRetry(turns []session.Turn, isRetry bool) (string, error) {
+// sendToClaudeWithRetry handles the actual sending with retry logic for stale profiles.
+// An empty model uses the configured one. A non-zero maxTokens overrides the
+// configured limit and disables thinking.
+func sendToClaudeWithRetry(model string, turns []session.Turn, maxTokens int, isRetry bool) (Response, error) {
+	var resp Response
+
 	// Load Ask configuration
 	cfg, err := config.Load()
 	if err != nil {
-		return "", fmt.Errorf("failed to load config: %w", err)
+		return resp, fmt.Errorf("failed to load config: %w", err)
+	}
+	if model != "" {
+		cfg.Model = model
+	}
+	if maxTokens > 0 {
+		cfg.MaxTokens = maxTokens
+		cfg.Thinking.Enabled = false
 	}
 
 	// Resolve model ID
 	modelID, err := cfg.ResolveModel()
 	if err != nil {
-		return "", fmt.Errorf("failed to resolve model: %w", err)
+		return resp, fmt.Errorf("failed to resolve model: %w", err)
 	}
 
 	// If this is a retry, invalidate the cache first
@@ -51,47 +86,31 @@ func sendToClaudeWithRetry(turns []session.Turn, isRetry bool) (string, error) {
 	// Ensure profile exists and get capabilities
 	profileArn, capabilities, err := ensureProfile(modelID)
 	if err != nil {
-		return "", fmt.Errorf("failed to setup model: %w", err)
+		return resp, fmt.Errorf("failed to setup model: %w", err)
 	}
+	logProfile(profileArn)
 
 	// Parse timeout
 	timeout, err := cfg.ParseTimeout()
 	if err != nil {
-		return "", fmt.Errorf("failed to parse timeout: %w", err)
+		return resp, fmt.Errorf("failed to parse timeout: %w", err)
 	}
 
 	// Load AWS configuration
-	awsCfg, err := awsconfig.LoadDefaultConfig(context.TODO())
+	awsCfg, err := config.LoadAWSConfig(context.TODO())
 	if err != nil {
-		return "", fmt.Errorf("AWS credentials not configured. Run: aws configure")
+		return resp, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
 	// Create Bedrock client
-	client := bedrockruntime.NewFromConfig(awsCfg)
+	client := bedrockruntime.NewFromConfig(awsCfg, withHTTPHeaders(cfg))
 
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	// Build message array from turns
-	var messages []types.Message
-	for _, turn := range turns {
-		var role types.ConversationRole
-		if turn.Role == "Human" {
-			role = types.ConversationRoleUser
-		} else {
-			role = types.ConversationRoleAssistant
-		}
-
-		messages = append(messages, types.Message{
-			Role: role,
-			Content: []types.ContentBlock{
-				&types.ContentBlockMemberText{
-					Value: turn.Content,
-				},
-			},
-		})
-	}
+	messages := buildMessages(turns)
 
 	// Build standard inference configuration
 	inferenceConfig := &types.InferenceConfiguration{
@@ -104,6 +123,7 @@ func sendToClaudeWithRetry(turns []session.Turn, isRetry bool) (string, error) {
 		ModelId:         aws.String(profileArn),
 		Messages:        messages,
 		InferenceConfig: inferenceConfig,
+		GuardrailConfig: guardrailConfig(cfg),
 	}
 
 	// Always try to set advanced features
@@ -121,12 +141,8 @@ func sendToClaudeWithRetry(turns []session.Turn, isRetry bool) (string, error) {
 		additionalFields["anthropic-beta"] = "context-1m-2025-08-07"
 	}
 
-	// Apply any bedrock config overrides
-	for key, value := range cfg.Bedrock {
-		if key != "thinking" && key != "enable_1m_context" {
-			additionalFields[key] = value
-		}
-	}
+	// Apply [bedrock.extra] settings
+	applyExtra(cfg.Bedrock.Extra, inferenceConfig, additionalFields)
 
 	if len(additionalFields) > 0 {
 		docMarshaler := document.NewLazyDocument(additionalFields)
@@ -137,33 +153,32 @@ func sendToClaudeWithRetry(turns []session.Turn, isRetry bool) (string, error) {
 	result, err := client.Converse(ctx, input)
 	if err != nil {
 		// Check for profile-related errors and retry once
-		if !isRetry && (strings.Contains(err.Error(), "profile") ||
-			strings.Contains(err.Error(), "not found") ||
-			strings.Contains(err.Error(), "does not exist")) {
+		classified := classifyError(err)
+		if !isRetry && classified.StaleProfile {
 			fmt.Println("Profile may be stale, refreshing...")
-			return sendToClaudeWithRetry(turns, true)
+			return sendToClaudeWithRetry(model, turns, maxTokens, true)
 		}
+		reportRequestID(err, nil)
+		return resp, classified
+	}
 
-		// Provide helpful error messages
-		errStr := err.Error()
-		if strings.Contains(errStr, "Extra inputs") {
-			return "", fmt.Errorf("this model doesn't support the configured features. Try disabling thinking: ask cfg thinking off")
-		}
-		if strings.Contains(errStr, "thinking") || strings.Contains(errStr, "budget_tokens") {
-			return "", fmt.Errorf("thinking configuration error. Try disabling with: ask cfg thinking off\nError: %w", err)
-		}
-		if strings.Contains(errStr, "inference profile") {
-			return "", fmt.Errorf("model requires additional setup. Try: ask cfg model opus")
-		}
-		if strings.Contains(errStr, "context-1m") {
-			return "", fmt.Errorf("1M context window requires tier 4 access. Remove 'enable_1m_context' from config")
+	logRequestID(result.ResultMetadata)
+
+	resp.ModelID = modelID
+	resp.InputTokens, resp.OutputTokens = CountTokens(result)
+	analytics.Record(modelID, resp.InputTokens, resp.OutputTokens)
+
+	if result.StopReason == types.StopReasonGuardrailIntervened {
+		var trace *types.GuardrailTraceAssessment
+		if result.Trace != nil {
+			trace = result.Trace.Guardrail
 		}
-		return "", fmt.Errorf("failed to invoke Claude: %w", err)
+		return resp, &GuardrailError{ID: cfg.Guardrail.ID, Topic: guardrailTopic(trace)}
 	}
 
 	// Extract response
 	if result.Output == nil {
-		return "", fmt.Errorf("empty response from Claude")
+		return resp, fmt.Errorf("empty response from Claude")
 	}
 
 	switch v := result.Output.(type) {
@@ -172,13 +187,14 @@ func sendToClaudeWithRetry(turns []session.Turn, isRetry bool) (string, error) {
 			// Look for text content (main response)
 			for _, content := range v.Value.Content {
 				if textBlock, ok := content.(*types.ContentBlockMemberText); ok {
-					return textBlock.Value, nil
+					resp.Text = textBlock.Value
+					return resp, nil
 				}
 			}
 		}
 	}
 
-	return "", fmt.Errorf("unexpected response format from Claude")
+	return resp, fmt.Errorf("unexpected response format from Claude")
 }
 
 // CountTokens returns the token count from a Converse response
@@ -0,0 +1,121 @@
+package bedrock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+)
+
+// tokenCountTTL is how long an API token count is reused for identical content
+const tokenCountTTL = 5 * time.Minute
+
+type tokenCountCache struct {
+	Counts map[string]tokenCountEntry `toml:"counts"`
+}
+
+type tokenCountEntry struct {
+	Tokens    int       `toml:"tokens"`
+	CountedAt time.Time `toml:"counted_at"`
+}
+
+// CountTokensForTurns asks Bedrock for the exact input token count of turns
+// as they would be sent to modelID. Results are cached by content hash.
+func CountTokensForTurns(ctx context.Context, turns []session.Turn, modelID string) (int, error) {
+	key := tokenCountKey(turns, modelID)
+
+	cacheMu.Lock()
+	cache := loadTokenCountCache()
+	cacheMu.Unlock()
+
+	if entry, ok := cache.Counts[key]; ok && time.Since(entry.CountedAt) < tokenCountTTL {
+		return entry.Tokens, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	awsCfg, err := config.LoadAWSConfig(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	client := bedrockruntime.NewFromConfig(awsCfg, withHTTPHeaders(cfg))
+	output, err := client.CountTokens(ctx, &bedrockruntime.CountTokensInput{
+		ModelId: aws.String(modelID),
+		Input: &types.CountTokensInputMemberConverse{
+			Value: types.ConverseTokensRequest{Messages: buildMessages(turns)},
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	if output.InputTokens == nil {
+		return 0, fmt.Errorf("token count missing from response")
+	}
+	tokens := int(*output.InputTokens)
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cache = loadTokenCountCache()
+	for k, entry := range cache.Counts {
+		if time.Since(entry.CountedAt) >= tokenCountTTL {
+			delete(cache.Counts, k)
+		}
+	}
+	cache.Counts[key] = tokenCountEntry{Tokens: tokens, CountedAt: time.Now()}
+	if err := saveTokenCountCache(cache); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to cache token count: %v\n", err)
+	}
+
+	return tokens, nil
+}
+
+// tokenCountKey hashes the model and every turn's role and content
+func tokenCountKey(turns []session.Turn, modelID string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", modelID)
+	for _, turn := range turns {
+		fmt.Fprintf(h, "%s %d\n%s\n", turn.Role, len(turn.Content), turn.Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func loadTokenCountCache() *tokenCountCache {
+	cache := &tokenCountCache{}
+	toml.DecodeFile(tokenCountCachePath(), cache)
+	if cache.Counts == nil {
+		cache.Counts = make(map[string]tokenCountEntry)
+	}
+	return cache
+}
+
+func saveTokenCountCache(cache *tokenCountCache) error {
+	if err := os.MkdirAll(filepath.Dir(tokenCountCachePath()), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(tokenCountCachePath())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return toml.NewEncoder(file).Encode(cache)
+}
+
+func tokenCountCachePath() string {
+	return filepath.Join(config.CachePath(), "token_counts.toml")
+}
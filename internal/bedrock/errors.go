@@ -0,0 +1,113 @@
+package bedrock
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/smithy-go"
+)
+
+// ErrorCategory groups Bedrock errors by what the user can do about them
+type ErrorCategory string
+
+const (
+	CategoryThrottling ErrorCategory = "throttling" // Too many requests or tokens; wait or raise quotas
+	CategoryCapacity   ErrorCategory = "capacity"   // Bedrock can't serve the model right now
+	CategoryAuth       ErrorCategory = "auth"       // Credentials or permissions
+	CategoryConfig     ErrorCategory = "config"     // A setting the model rejects
+	CategoryModel      ErrorCategory = "model"      // The model or its profile is missing or failed
+	CategoryUnknown    ErrorCategory = "unknown"
+)
+
+// BedrockError wraps an error from a Bedrock call with its category and a
+// suggestion for fixing it
+type BedrockError struct {
+	Err        error
+	Category   ErrorCategory
+	Code       string // AWS error code, e.g. ThrottlingException, when known
+	Suggestion string
+	// StaleProfile means the cached inference profile may be gone, so the
+	// call is worth retrying once after refreshing it
+	StaleProfile bool
+}
+
+func (e BedrockError) Error() string {
+	msg := fmt.Sprintf("failed to invoke Claude (%s): %v", e.Category, e.Err)
+	if e.Category == CategoryUnknown {
+		msg = fmt.Sprintf("failed to invoke Claude: %v", e.Err)
+	}
+	if e.Suggestion != "" {
+		msg += "\n" + e.Suggestion
+	}
+	return msg
+}
+
+func (e BedrockError) Unwrap() error {
+	return e.Err
+}
+
+// classifyError categorizes an error returned by Converse or ConverseStream
+func classifyError(err error) BedrockError {
+	be := BedrockError{Err: err, Category: CategoryUnknown}
+	msg := strings.ToLower(err.Error())
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		be.Code = apiErr.ErrorCode()
+	}
+
+	switch be.Code {
+	case "ThrottlingException":
+		be.Category = CategoryThrottling
+		be.Suggestion = "Bedrock is throttling requests. Wait a minute and retry, or set fallbacks: ask cfg fallback-models sonnet haiku"
+	case "ServiceQuotaExceededException":
+		be.Category = CategoryThrottling
+		be.Suggestion = "A Bedrock quota was exceeded. Request an increase in the Service Quotas console, or lower: ask cfg max-tokens"
+	case "ServiceUnavailableException", "ModelNotReadyException", "InternalServerException":
+		be.Category = CategoryCapacity
+		be.Suggestion = "Bedrock can't serve the model right now. Retry shortly, or set fallbacks: ask cfg fallback-models sonnet haiku"
+	case "AccessDeniedException":
+		be.Category = CategoryAuth
+		be.Suggestion = "Check that model access is granted in the Bedrock console and that your IAM policy allows bedrock:InvokeModel*"
+	case "UnrecognizedClientException", "InvalidSignatureException", "ExpiredTokenException", "ExpiredToken":
+		be.Category = CategoryAuth
+		be.Suggestion = "AWS credentials are invalid or expired. Run: aws configure (or aws sso login)"
+	case "ResourceNotFoundException":
+		be.Category = CategoryModel
+		be.StaleProfile = true
+		be.Suggestion = "The model or inference profile was not found. Check the model is available in your region: ask cfg models"
+	case "ModelTimeoutException":
+		be.Category = CategoryModel
+		be.Suggestion = "The model took too long to respond. Retry, or send less with: ask chat --budget"
+	case "ModelErrorException", "ModelStreamErrorException":
+		be.Category = CategoryModel
+		be.Suggestion = "The model failed to process the request. Retry, or try another model: ask cfg model sonnet"
+	case "ValidationException":
+		be.Category = CategoryConfig
+		switch {
+		case strings.Contains(msg, "extra inputs"):
+			be.Suggestion = "This model doesn't support the configured features. Try disabling thinking: ask cfg thinking off"
+		case strings.Contains(msg, "context-1m"):
+			be.Suggestion = "The 1M context window requires tier 4 access. Try: ask cfg context standard"
+		case strings.Contains(msg, "thinking") || strings.Contains(msg, "budget_tokens"):
+			be.Suggestion = "Thinking configuration error. Try disabling it: ask cfg thinking off"
+		case strings.Contains(msg, "inference profile"):
+			be.Category = CategoryModel
+			be.Suggestion = "The model requires additional setup. Try: ask cfg model opus"
+		case strings.Contains(msg, "too long") || strings.Contains(msg, "too many tokens"):
+			be.Suggestion = "The session is larger than the model accepts. Send fewer turns with: ask chat --budget"
+		default:
+			be.Suggestion = "Bedrock rejected the request. Check settings that differ from defaults: ask cfg diff"
+		}
+	}
+
+	// Profiles ask created can disappear; the message is all that says so
+	if !be.StaleProfile && (strings.Contains(msg, "profile") ||
+		strings.Contains(msg, "not found") ||
+		strings.Contains(msg, "does not exist")) {
+		be.StaleProfile = true
+	}
+
+	return be
+}
@@ -0,0 +1,89 @@
+package bedrock
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		code       string
+		message    string
+		category   ErrorCategory
+		suggestion string // Substring of the suggestion
+		stale      bool
+	}{
+		{"ThrottlingException", "Too many requests", CategoryThrottling, "fallback-models", false},
+		{"ServiceQuotaExceededException", "Quota exceeded", CategoryThrottling, "Service Quotas", false},
+		{"ServiceUnavailableException", "Service unavailable", CategoryCapacity, "can't serve the model", false},
+		{"ModelNotReadyException", "Model is warming up", CategoryCapacity, "can't serve the model", false},
+		{"InternalServerException", "Internal error", CategoryCapacity, "can't serve the model", false},
+		{"AccessDeniedException", "You don't have access", CategoryAuth, "bedrock:InvokeModel", false},
+		{"UnrecognizedClientException", "Invalid token", CategoryAuth, "aws configure", false},
+		{"ExpiredTokenException", "Token expired", CategoryAuth, "aws configure", false},
+		{"ResourceNotFoundException", "Model missing", CategoryModel, "ask cfg models", true},
+		{"ModelTimeoutException", "Timed out", CategoryModel, "--budget", false},
+		{"ModelErrorException", "Model failed", CategoryModel, "ask cfg model sonnet", false},
+		{"ValidationException", "extra inputs are not permitted", CategoryConfig, "ask cfg thinking off", false},
+		{"ValidationException", "anthropic-beta: context-1m not allowed", CategoryConfig, "ask cfg context standard", false},
+		{"ValidationException", "budget_tokens must be less than max_tokens", CategoryConfig, "ask cfg thinking off", false},
+		{"ValidationException", "Invocation with on-demand throughput isn't supported; use an inference profile", CategoryModel, "ask cfg model opus", true},
+		{"ValidationException", "Input is too long for requested model", CategoryConfig, "--budget", false},
+		{"ValidationException", "Malformed input request", CategoryConfig, "ask cfg diff", false},
+		{"SomethingNewException", "Unexpected", CategoryUnknown, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code+"/"+tt.message, func(t *testing.T) {
+			apiErr := &smithy.GenericAPIError{Code: tt.code, Message: tt.message}
+			// The SDK wraps service errors in operation errors
+			err := fmt.Errorf("operation error Bedrock Runtime: Converse, %w", apiErr)
+
+			be := classifyError(err)
+			if be.Code != tt.code {
+				t.Errorf("Code = %q, want %q", be.Code, tt.code)
+			}
+			if be.Category != tt.category {
+				t.Errorf("Category = %q, want %q", be.Category, tt.category)
+			}
+			if tt.suggestion == "" && be.Suggestion != "" {
+				t.Errorf("Suggestion = %q, want none", be.Suggestion)
+			}
+			if !strings.Contains(be.Suggestion, tt.suggestion) {
+				t.Errorf("Suggestion = %q, want it to mention %q", be.Suggestion, tt.suggestion)
+			}
+			if be.StaleProfile != tt.stale {
+				t.Errorf("StaleProfile = %v, want %v", be.StaleProfile, tt.stale)
+			}
+
+			var wrapped error = be
+			var got smithy.APIError
+			if !errors.As(wrapped, &got) || got.ErrorCode() != tt.code {
+				t.Errorf("errors.As through Unwrap found %v, want code %s", got, tt.code)
+			}
+			if !errors.Is(wrapped, apiErr) {
+				t.Errorf("errors.Is(BedrockError, apiErr) = false")
+			}
+			if !strings.Contains(be.Error(), tt.message) {
+				t.Errorf("Error() = %q, want it to include %q", be.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorWithoutCode(t *testing.T) {
+	be := classifyError(errors.New("inference profile does not exist"))
+	if be.Category != CategoryUnknown || be.Code != "" {
+		t.Errorf("got category %q code %q, want unknown with no code", be.Category, be.Code)
+	}
+	if !be.StaleProfile {
+		t.Error("StaleProfile = false, want true for a missing profile message")
+	}
+	if strings.Contains(be.Error(), "(unknown)") {
+		t.Errorf("Error() = %q, want no category for unknown errors", be.Error())
+	}
+}
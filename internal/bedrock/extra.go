@@ -0,0 +1,30 @@
+package bedrock
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/rana/ask/internal/config"
+)
+
+// applyExtra copies [bedrock.extra] settings into a request. Typed fields go
+// into the inference configuration; Raw keys fill additional model request
+// fields without replacing ones Ask already set.
+func applyExtra(extra config.BedrockExtra, inference *types.InferenceConfiguration, additional map[string]interface{}) {
+	if extra.TopPNucleus != 0 {
+		inference.TopP = aws.Float32(float32(extra.TopPNucleus))
+	}
+	if len(extra.StopSequences) > 0 {
+		inference.StopSequences = extra.StopSequences
+	}
+
+	// Converse has no top_k parameter; Anthropic models take it as an extra field
+	if extra.TopK != 0 {
+		additional[config.ExtraTopK] = extra.TopK
+	}
+
+	for key, value := range extra.Raw {
+		if _, exists := additional[key]; !exists {
+			additional[key] = value
+		}
+	}
+}
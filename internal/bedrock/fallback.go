@@ -0,0 +1,74 @@
+package bedrock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+)
+
+// callWithFallback streams from model, then from each of cfg.FallbackModels
+// in turn while the previous one fails for lack of capacity
+func callWithFallback(ctx context.Context, model string, turns []session.Turn, callback StreamCallback, thinking ThinkingCallback, restart RestartCallback) (int, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load config: %w", err)
+	}
+	if model == "" {
+		model = cfg.Model
+	}
+
+	chain := []string{model}
+	for _, fallback := range cfg.FallbackModels {
+		if fallback != model {
+			chain = append(chain, fallback)
+		}
+	}
+
+	delivered := false
+	trackedCallback := func(chunk string, tokenCount int) error {
+		delivered = true
+		return callback(chunk, tokenCount)
+	}
+	var trackedThinking ThinkingCallback
+	if thinking != nil {
+		trackedThinking = func(chunk string) error {
+			delivered = true
+			return thinking(chunk)
+		}
+	}
+
+	for i := 0; ; i++ {
+		current := chain[i]
+		tokens, err := streamToClaudeWithRetry(ctx, current, turns, trackedCallback, trackedThinking, restart, false)
+		if err == nil || !isCapacityError(err) || i == len(chain)-1 {
+			return tokens, err
+		}
+
+		// Without restart the caller can't take back what it already received
+		if delivered && restart == nil {
+			return tokens, err
+		}
+
+		fmt.Fprintf(os.Stderr, "Falling back from %s to %s due to capacity error\n", current, chain[i+1])
+		if delivered {
+			if err := restart(); err != nil {
+				return 0, fmt.Errorf("failed to discard partial response: %w", err)
+			}
+			delivered = false
+		}
+	}
+}
+
+// isCapacityError reports whether err means Bedrock is too busy to serve the
+// model right now, as opposed to a problem with the request itself
+func isCapacityError(err error) bool {
+	var throttling *types.ThrottlingException
+	var unavailable *types.ServiceUnavailableException
+	var notReady *types.ModelNotReadyException
+	return errors.As(err, &throttling) || errors.As(err, &unavailable) || errors.As(err, &notReady)
+}
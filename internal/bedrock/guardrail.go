@@ -0,0 +1,144 @@
+package bedrock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/rana/ask/internal/config"
+)
+
+// GuardrailError reports a request or response blocked by a guardrail
+type GuardrailError struct {
+	ID    string
+	Topic string
+}
+
+func (e *GuardrailError) Error() string {
+	return fmt.Sprintf("Content blocked by Guardrail %s: %s", e.ID, e.Topic)
+}
+
+// guardrailConfig returns the guardrail for Converse, or nil if none is configured
+func guardrailConfig(cfg *config.Config) *types.GuardrailConfiguration {
+	if !cfg.Guardrail.Enabled() {
+		return nil
+	}
+	return &types.GuardrailConfiguration{
+		GuardrailIdentifier: aws.String(cfg.Guardrail.ID),
+		GuardrailVersion:    aws.String(cfg.Guardrail.Version),
+		Trace:               types.GuardrailTraceEnabled,
+	}
+}
+
+// guardrailStreamConfig returns the guardrail for ConverseStream, or nil if none is configured
+func guardrailStreamConfig(cfg *config.Config) *types.GuardrailStreamConfiguration {
+	if !cfg.Guardrail.Enabled() {
+		return nil
+	}
+	return &types.GuardrailStreamConfiguration{
+		GuardrailIdentifier: aws.String(cfg.Guardrail.ID),
+		GuardrailVersion:    aws.String(cfg.Guardrail.Version),
+		Trace:               types.GuardrailTraceEnabled,
+	}
+}
+
+// guardrailTopic names what a guardrail trace flagged, such as a denied
+// topic or content filter, for the blocked message
+func guardrailTopic(trace *types.GuardrailTraceAssessment) string {
+	if trace == nil {
+		return "policy violation"
+	}
+
+	var assessments []types.GuardrailAssessment
+	for _, a := range trace.InputAssessment {
+		assessments = append(assessments, a)
+	}
+	for _, list := range trace.OutputAssessments {
+		assessments = append(assessments, list...)
+	}
+	if topic := assessmentTopic(assessments); topic != "" {
+		return topic
+	}
+	if reason := aws.ToString(trace.ActionReason); reason != "" {
+		return reason
+	}
+	return "policy violation"
+}
+
+// assessmentTopic lists the topics, content filters and words that were detected
+func assessmentTopic(assessments []types.GuardrailAssessment) string {
+	seen := make(map[string]bool)
+	for _, a := range assessments {
+		if a.TopicPolicy != nil {
+			for _, t := range a.TopicPolicy.Topics {
+				seen[aws.ToString(t.Name)] = true
+			}
+		}
+		if a.ContentPolicy != nil {
+			for _, f := range a.ContentPolicy.Filters {
+				seen[strings.ToLower(string(f.Type))] = true
+			}
+		}
+		if a.WordPolicy != nil {
+			if len(a.WordPolicy.CustomWords) > 0 || len(a.WordPolicy.ManagedWordLists) > 0 {
+				seen["blocked words"] = true
+			}
+		}
+		if a.SensitiveInformationPolicy != nil {
+			if len(a.SensitiveInformationPolicy.PiiEntities) > 0 || len(a.SensitiveInformationPolicy.Regexes) > 0 {
+				seen["sensitive information"] = true
+			}
+		}
+	}
+	delete(seen, "")
+
+	topics := make([]string, 0, len(seen))
+	for topic := range seen {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return strings.Join(topics, ", ")
+}
+
+// TestGuardrail checks text against the configured guardrail without calling
+// a model. It returns nil if the text would pass and a GuardrailError if not.
+func TestGuardrail(ctx context.Context, text string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.Guardrail.Enabled() {
+		return fmt.Errorf("no guardrail configured. Run: ask cfg guardrail set <id> <version>")
+	}
+
+	awsCfg, err := config.LoadAWSConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	client := bedrockruntime.NewFromConfig(awsCfg, withHTTPHeaders(cfg))
+
+	output, err := client.ApplyGuardrail(ctx, &bedrockruntime.ApplyGuardrailInput{
+		GuardrailIdentifier: aws.String(cfg.Guardrail.ID),
+		GuardrailVersion:    aws.String(cfg.Guardrail.Version),
+		Source:              types.GuardrailContentSourceInput,
+		Content: []types.GuardrailContentBlock{
+			&types.GuardrailContentBlockMemberText{Value: types.GuardrailTextBlock{Text: aws.String(text)}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply guardrail: %w", err)
+	}
+
+	if output.Action != types.GuardrailActionGuardrailIntervened {
+		return nil
+	}
+	topic := assessmentTopic(output.Assessments)
+	if topic == "" {
+		topic = "policy violation"
+	}
+	return &GuardrailError{ID: cfg.Guardrail.ID, Topic: topic}
+}
@@ -0,0 +1,103 @@
+package bedrock
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+func TestGuardrailTopic(t *testing.T) {
+	topic := types.GuardrailAssessment{
+		TopicPolicy: &types.GuardrailTopicPolicyAssessment{
+			Topics: []types.GuardrailTopic{{Name: aws.String("Investment advice"), Action: types.GuardrailTopicPolicyActionBlocked}},
+		},
+	}
+	content := types.GuardrailAssessment{
+		ContentPolicy: &types.GuardrailContentPolicyAssessment{
+			Filters: []types.GuardrailContentFilter{
+				{Type: types.GuardrailContentFilterTypeViolence},
+				{Type: types.GuardrailContentFilterTypeHate},
+			},
+		},
+	}
+	words := types.GuardrailAssessment{
+		WordPolicy: &types.GuardrailWordPolicyAssessment{
+			CustomWords: []types.GuardrailCustomWord{{Match: aws.String("darn")}},
+		},
+	}
+	pii := types.GuardrailAssessment{
+		SensitiveInformationPolicy: &types.GuardrailSensitiveInformationPolicyAssessment{
+			PiiEntities: []types.GuardrailPiiEntityFilter{{Type: types.GuardrailPiiEntityTypeEmail, Match: aws.String("a@b.c")}},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		trace *types.GuardrailTraceAssessment
+		want  string
+	}{
+		{"no trace", nil, "policy violation"},
+		{
+			name:  "denied topic in input",
+			trace: &types.GuardrailTraceAssessment{InputAssessment: map[string]types.GuardrailAssessment{"g1": topic}},
+			want:  "Investment advice",
+		},
+		{
+			name:  "content filters in output",
+			trace: &types.GuardrailTraceAssessment{OutputAssessments: map[string][]types.GuardrailAssessment{"g1": {content}}},
+			want:  "hate, violence",
+		},
+		{
+			name:  "blocked words",
+			trace: &types.GuardrailTraceAssessment{InputAssessment: map[string]types.GuardrailAssessment{"g1": words}},
+			want:  "blocked words",
+		},
+		{
+			name:  "PII",
+			trace: &types.GuardrailTraceAssessment{OutputAssessments: map[string][]types.GuardrailAssessment{"g1": {pii}}},
+			want:  "sensitive information",
+		},
+		{
+			name: "input and output combined, deduplicated",
+			trace: &types.GuardrailTraceAssessment{
+				InputAssessment:   map[string]types.GuardrailAssessment{"g1": topic},
+				OutputAssessments: map[string][]types.GuardrailAssessment{"g1": {pii, topic}},
+			},
+			want: "Investment advice, sensitive information",
+		},
+		{
+			name:  "action reason without assessments",
+			trace: &types.GuardrailTraceAssessment{ActionReason: aws.String("Guardrail blocked.")},
+			want:  "Guardrail blocked.",
+		},
+		{"empty trace", &types.GuardrailTraceAssessment{}, "policy violation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := guardrailTopic(tt.trace); got != tt.want {
+				t.Errorf("guardrailTopic() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssessmentTopicIgnoresEmptyPolicies(t *testing.T) {
+	assessments := []types.GuardrailAssessment{{
+		WordPolicy:                 &types.GuardrailWordPolicyAssessment{},
+		SensitiveInformationPolicy: &types.GuardrailSensitiveInformationPolicyAssessment{},
+		TopicPolicy:                &types.GuardrailTopicPolicyAssessment{Topics: []types.GuardrailTopic{{}}},
+	}}
+	if got := assessmentTopic(assessments); got != "" {
+		t.Errorf("assessmentTopic() = %q, want empty", got)
+	}
+}
+
+func TestGuardrailErrorMessage(t *testing.T) {
+	err := &GuardrailError{ID: "gr-abc123", Topic: "Investment advice"}
+	want := "Content blocked by Guardrail gr-abc123: Investment advice"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
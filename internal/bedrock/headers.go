@@ -0,0 +1,18 @@
+package bedrock
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/rana/ask/internal/config"
+)
+
+// withHTTPHeaders injects configured custom headers into every runtime request
+func withHTTPHeaders(cfg *config.Config) func(*bedrockruntime.Options) {
+	headers := cfg.HTTPHeaders()
+
+	return func(o *bedrockruntime.Options) {
+		for name, value := range headers {
+			o.APIOptions = append(o.APIOptions, smithyhttp.SetHeaderValue(name, value))
+		}
+	}
+}
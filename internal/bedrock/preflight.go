@@ -0,0 +1,65 @@
+package bedrock
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/session"
+)
+
+// Preflight sends a one-token "Hi" to check credentials, profile discovery
+// and model access. Thinking and extra parameters are left out so that only
+// access is tested. It uses model instead of the configured one when non-empty.
+func Preflight(ctx context.Context, model string) (modelID, profileArn string, err error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load config: %w", err)
+	}
+	if model != "" {
+		cfg.Model = model
+	}
+
+	modelID, err = cfg.ResolveModel()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve model: %w", err)
+	}
+
+	profileArn, _, err = ensureProfile(modelID)
+	if err != nil {
+		return modelID, "", fmt.Errorf("failed to setup model: %w", err)
+	}
+
+	if err := invokeOneToken(ctx, cfg, profileArn, "Hi"); err != nil {
+		return modelID, profileArn, err
+	}
+
+	return modelID, profileArn, nil
+}
+
+// invokeOneToken sends text to profileArn asking for a single token
+func invokeOneToken(ctx context.Context, cfg *config.Config, profileArn, text string) error {
+	awsCfg, err := config.LoadAWSConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	client := bedrockruntime.NewFromConfig(awsCfg, withHTTPHeaders(cfg))
+	result, err := client.Converse(ctx, &bedrockruntime.ConverseInput{
+		ModelId:  aws.String(profileArn),
+		Messages: buildMessages([]session.Turn{{Number: 1, Role: "Human", Content: text}}),
+		InferenceConfig: &types.InferenceConfiguration{
+			MaxTokens: aws.Int32(1),
+		},
+	})
+	if err != nil {
+		reportRequestID(err, nil)
+		return fmt.Errorf("model not accessible: %w", err)
+	}
+	logRequestID(result.ResultMetadata)
+
+	return nil
+}
@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrock"
 	"github.com/rana/ask/internal/config"
 )
@@ -17,6 +16,16 @@ type ModelCapabilities struct {
 	Supports1MContext bool
 }
 
+// Capabilities returns what ask assumes modelID supports
+func Capabilities(modelID string) ModelCapabilities {
+	return getModelCapabilities(modelID)
+}
+
+// CachedProfileARN returns the inference profile cached for modelID, if any
+func CachedProfileARN(modelID string) (string, bool) {
+	return getCachedProfile(deriveProfileName(modelID))
+}
+
 // getModelCapabilities returns capabilities based on model ID patterns
 func getModelCapabilities(modelID string) ModelCapabilities {
 	lower := strings.ToLower(modelID)
@@ -38,6 +47,13 @@ func getModelCapabilities(modelID string) ModelCapabilities {
 // ensureProfile discovers the system-provided inference profile for a model
 func ensureProfile(modelID string) (string, ModelCapabilities, error) {
 	caps := getModelCapabilities(modelID)
+	askConfig, _ := config.Load()
+
+	// Provisioned throughput is its own model ID; no profile is involved
+	if askConfig != nil && askConfig.ProvisionedARN != "" {
+		return askConfig.ProvisionedARN, caps, nil
+	}
+
 	profileName := deriveProfileName(modelID)
 
 	// Check cache first
@@ -46,17 +62,20 @@ func ensureProfile(modelID string) (string, ModelCapabilities, error) {
 	}
 
 	// Discover system profile
-	cfg, err := awsconfig.LoadDefaultConfig(context.TODO())
+	cfg, err := config.LoadAWSConfig(context.TODO())
 	if err != nil {
 		return "", caps, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
 	client := bedrock.NewFromConfig(cfg)
 
-	askConfig, _ := config.Load()
 	prefer1M := askConfig != nil && askConfig.Uses1MContext()
+	filter := ""
+	if askConfig != nil {
+		filter = askConfig.ProfileFilter
+	}
 
-	profileArn, err := discoverSystemProfile(context.Background(), client, modelID, prefer1M)
+	profileArn, err := discoverSystemProfile(context.Background(), client, modelID, prefer1M, filter)
 	if err != nil {
 		return "", caps, fmt.Errorf(`no system inference profile found for this model
 
@@ -116,8 +135,9 @@ func deriveProfileName(modelID string) string {
 	}
 }
 
-// discoverSystemProfile finds AWS-provided inference profile
-func discoverSystemProfile(ctx context.Context, client *bedrock.Client, modelID string, prefer1M bool) (string, error) {
+// discoverSystemProfile finds AWS-provided inference profile, considering
+// only ARNs that contain filter when it is set
+func discoverSystemProfile(ctx context.Context, client *bedrock.Client, modelID string, prefer1M bool, filter string) (string, error) {
 	input := &bedrock.ListInferenceProfilesInput{
 		MaxResults: aws.Int32(100),
 	}
@@ -149,6 +169,10 @@ func discoverSystemProfile(ctx context.Context, client *bedrock.Client, modelID
 		}
 
 		profileArn := *profile.InferenceProfileArn
+		if filter != "" && !strings.Contains(profileArn, filter) {
+			continue
+		}
+
 		profileName := ""
 		if profile.InferenceProfileName != nil {
 			profileName = strings.ToLower(*profile.InferenceProfileName)
@@ -196,11 +220,17 @@ func discoverSystemProfile(ctx context.Context, client *bedrock.Client, modelID
 		return standardProfile, nil
 	}
 
+	if filter != "" {
+		return "", fmt.Errorf("no %s inference profile found matching profile filter '%s'", modelType, filter)
+	}
 	return "", fmt.Errorf("no %s inference profile found", modelType)
 }
 
 // invalidateCachedProfile removes profile from cache (used on errors)
 func invalidateCachedProfile(profileName string) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
 	cache, _ := loadProfileCache()
 	delete(cache.Profiles, profileName)
 	saveProfileCache(cache)
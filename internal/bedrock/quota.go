@@ -0,0 +1,103 @@
+package bedrock
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/rana/ask/internal/config"
+)
+
+// Quota is one service quota as reported by AWS Service Quotas
+type Quota struct {
+	Name       string  `json:"QuotaName"`
+	Code       string  `json:"QuotaCode"`
+	Value      float64 `json:"Value"`
+	Unit       string  `json:"Unit"`
+	Adjustable bool    `json:"Adjustable"`
+}
+
+// ListServiceQuotas returns the quotas applied to the account for a service
+// code such as "bedrock". The Service Quotas SDK isn't a dependency, so its
+// JSON API is called directly with a SigV4-signed request.
+func ListServiceQuotas(ctx context.Context, serviceCode string) ([]Quota, error) {
+	awsCfg, err := config.LoadAWSConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	creds, err := awsCfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AWS credentials: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("https://servicequotas.%s.amazonaws.com/", awsCfg.Region)
+	signer := v4.NewSigner()
+
+	var quotas []Quota
+	nextToken := ""
+	for {
+		input := map[string]interface{}{"ServiceCode": serviceCode, "MaxResults": 100}
+		if nextToken != "" {
+			input["NextToken"] = nextToken
+		}
+		body, err := json.Marshal(input)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+		req.Header.Set("X-Amz-Target", "ServiceQuotasV20190624.ListServiceQuotas")
+
+		sum := sha256.Sum256(body)
+		if err := signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), "servicequotas", awsCfg.Region, time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to sign request: %w", err)
+		}
+
+		resp, err := awsCfg.HTTPClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call Service Quotas: %w", err)
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Service Quotas response: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			var apiErr struct {
+				Type    string `json:"__type"`
+				Message string `json:"message"`
+			}
+			json.Unmarshal(respBody, &apiErr)
+			if apiErr.Message == "" {
+				apiErr.Message = resp.Status
+			}
+			return nil, fmt.Errorf("Service Quotas returned %s: %s", apiErr.Type, apiErr.Message)
+		}
+
+		var page struct {
+			Quotas    []Quota `json:"Quotas"`
+			NextToken string  `json:"NextToken"`
+		}
+		if err := json.Unmarshal(respBody, &page); err != nil {
+			return nil, fmt.Errorf("failed to decode Service Quotas response: %w", err)
+		}
+		quotas = append(quotas, page.Quotas...)
+
+		if page.NextToken == "" {
+			return quotas, nil
+		}
+		nextToken = page.NextToken
+	}
+}
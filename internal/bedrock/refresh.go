@@ -0,0 +1,90 @@
+package bedrock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrock"
+	"github.com/rana/ask/internal/config"
+)
+
+// refreshThreshold is the fraction of profileTTL after which a cached
+// profile is re-discovered in the background
+const refreshThreshold = 0.8
+
+// RefreshProfiles re-discovers cached profiles nearing expiry so the next
+// command finds a fresh cache. It is meant to run in its own goroutine and
+// stops early when ctx is cancelled.
+func RefreshProfiles(ctx context.Context, prefer1M bool) {
+	cacheMu.Lock()
+	cache, err := loadProfileCache()
+	cacheMu.Unlock()
+	if err != nil {
+		return
+	}
+
+	stale := make(map[string]ProfileEntry)
+	for name, entry := range cache.Profiles {
+		if time.Since(entry.CreatedAt) > time.Duration(float64(profileTTL)*refreshThreshold) {
+			stale[name] = entry
+		}
+	}
+
+	if len(stale) == 0 {
+		return
+	}
+
+	awsCfg, err := config.LoadAWSConfig(ctx)
+	if err != nil {
+		logRefresh("failed to load AWS config: %v", err)
+		return
+	}
+
+	client := bedrock.NewFromConfig(awsCfg)
+
+	filter := ""
+	if askCfg, err := config.Load(); err == nil {
+		filter = askCfg.ProfileFilter
+	}
+
+	for name, entry := range stale {
+		if ctx.Err() != nil {
+			logRefresh("refresh cancelled")
+			return
+		}
+
+		age := time.Since(entry.CreatedAt).Round(time.Hour)
+
+		profileArn, err := discoverSystemProfile(ctx, client, entry.ModelID, prefer1M, filter)
+		if err != nil {
+			logRefresh("failed to refresh %s (age %s): %v", name, age, err)
+			continue
+		}
+
+		if err := setCachedProfile(name, profileArn, entry.ModelID); err != nil {
+			logRefresh("failed to cache %s: %v", name, err)
+			continue
+		}
+
+		logRefresh("refreshed %s (age %s): %s", name, age, profileArn)
+	}
+}
+
+// logRefresh appends a timestamped line to ~/.ask/cache/refresh.log
+func logRefresh(format string, args ...interface{}) {
+	path := filepath.Join(filepath.Dir(profileCachePath()), "refresh.log")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	fmt.Fprintf(file, "[%s] %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
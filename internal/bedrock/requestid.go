@@ -0,0 +1,58 @@
+package bedrock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// verbose prints the profile and request ID of every Bedrock call, not only failed ones
+var verbose bool
+
+// SetVerbose turns request ID logging on for successful calls too
+func SetVerbose(v bool) {
+	verbose = v
+}
+
+// requestID returns the x-amzn-requestid of a failed call, or of the call
+// described by metadata when the error doesn't carry one
+func requestID(err error, metadata *middleware.Metadata) string {
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) && respErr.RequestID != "" {
+		return respErr.RequestID
+	}
+	if metadata != nil {
+		if id, ok := awsmiddleware.GetRequestIDMetadata(*metadata); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// reportRequestID prints the request ID of a failed call to stderr
+func reportRequestID(err error, metadata *middleware.Metadata) {
+	if id := requestID(err, metadata); id != "" {
+		fmt.Fprintf(os.Stderr, "Request ID: %s (include this when reporting issues)\n", id)
+	}
+}
+
+// logRequestID prints the request ID of a successful call when verbose
+func logRequestID(metadata middleware.Metadata) {
+	if !verbose {
+		return
+	}
+	if id, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+		fmt.Fprintf(os.Stderr, "Request ID: %s\n", id)
+	}
+}
+
+// logProfile prints the inference profile or model a request goes to when verbose
+func logProfile(arn string) {
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Profile: %s\n", arn)
+	}
+}
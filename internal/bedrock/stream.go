@@ -2,14 +2,18 @@ package bedrock
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"io"
+	"net"
+	"os"
+	"syscall"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/rana/ask/internal/analytics"
 	"github.com/rana/ask/internal/config"
 	"github.com/rana/ask/internal/session"
 )
@@ -17,17 +21,41 @@ import (
 // StreamCallback is called for each chunk of streaming response
 type StreamCallback func(chunk string, tokenCount int) error
 
+// ThinkingCallback is called for each chunk of extended thinking
+type ThinkingCallback func(chunk string) error
+
+// RestartCallback is called before a dropped stream is requested again,
+// so the caller can discard the chunks it already received
+type RestartCallback func() error
+
 // StreamToClaudeWithHistory sends conversation history and streams the response
 func StreamToClaudeWithHistory(ctx context.Context, turns []session.Turn, callback StreamCallback) (int, error) {
-	return streamToClaudeWithRetry(ctx, turns, callback, false)
+	return callWithFallback(ctx, "", turns, callback, nil, nil)
+}
+
+// StreamToClaudeWithModel streams like StreamToClaudeWithHistory, using model
+// instead of the configured one when non-empty
+func StreamToClaudeWithModel(ctx context.Context, model string, turns []session.Turn, callback StreamCallback) (int, error) {
+	return callWithFallback(ctx, model, turns, callback, nil, nil)
 }
 
-func streamToClaudeWithRetry(ctx context.Context, turns []session.Turn, callback StreamCallback, isRetry bool) (int, error) {
+// StreamToClaudeWithThinking streams like StreamToClaudeWithModel and also
+// passes thinking chunks to thinking, which are otherwise discarded.
+// If the connection drops mid-stream, restart is called and the
+// request is sent again.
+func StreamToClaudeWithThinking(ctx context.Context, model string, turns []session.Turn, callback StreamCallback, thinking ThinkingCallback, restart RestartCallback) (int, error) {
+	return callWithFallback(ctx, model, turns, callback, thinking, restart)
+}
+
+func streamToClaudeWithRetry(ctx context.Context, model string, turns []session.Turn, callback StreamCallback, thinking ThinkingCallback, restart RestartCallback, isRetry bool) (int, error) {
 	// Load Ask configuration
 	cfg, err := config.Load()
 	if err != nil {
 		return 0, fmt.Errorf("failed to load config: %w", err)
 	}
+	if model != "" {
+		cfg.Model = model
+	}
 
 	// Resolve model ID
 	modelID, err := cfg.ResolveModel()
@@ -47,34 +75,19 @@ func streamToClaudeWithRetry(ctx context.Context, turns []session.Turn, callback
 		return 0, fmt.Errorf("failed to setup model: %w", err)
 	}
 
+	logProfile(profileArn)
+
 	// Load AWS configuration
-	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	awsCfg, err := config.LoadAWSConfig(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("AWS credentials not configured. Run: aws configure")
+		return 0, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
 	// Create Bedrock client
-	client := bedrockruntime.NewFromConfig(awsCfg)
+	client := bedrockruntime.NewFromConfig(awsCfg, withHTTPHeaders(cfg))
 
 	// Build message array from turns
-	var messages []types.Message
-	for _, turn := range turns {
-		var role types.ConversationRole
-		if turn.Role == "Human" {
-			role = types.ConversationRoleUser
-		} else {
-			role = types.ConversationRoleAssistant
-		}
-
-		messages = append(messages, types.Message{
-			Role: role,
-			Content: []types.ContentBlock{
-				&types.ContentBlockMemberText{
-					Value: turn.Content,
-				},
-			},
-		})
-	}
+	messages := buildMessages(turns)
 
 	// Build standard inference configuration
 	inferenceConfig := &types.InferenceConfiguration{
@@ -87,6 +100,7 @@ func streamToClaudeWithRetry(ctx context.Context, turns []session.Turn, callback
 		ModelId:         aws.String(profileArn),
 		Messages:        messages,
 		InferenceConfig: inferenceConfig,
+		GuardrailConfig: guardrailStreamConfig(cfg),
 	}
 
 	// Always try to set advanced features
@@ -104,12 +118,8 @@ func streamToClaudeWithRetry(ctx context.Context, turns []session.Turn, callback
 		additionalFields["anthropic-beta"] = "context-1m-2025-08-07"
 	}
 
-	// Apply any bedrock config overrides
-	for key, value := range cfg.Bedrock {
-		if key != "thinking" && key != "enable_1m_context" {
-			additionalFields[key] = value
-		}
-	}
+	// Apply [bedrock.extra] settings
+	applyExtra(cfg.Bedrock.Extra, inferenceConfig, additionalFields)
 
 	if len(additionalFields) > 0 {
 		docMarshaler := document.NewLazyDocument(additionalFields)
@@ -120,46 +130,83 @@ func streamToClaudeWithRetry(ctx context.Context, turns []session.Turn, callback
 	output, err := client.ConverseStream(ctx, input)
 	if err != nil {
 		// Check for profile-related errors and retry once
-		if !isRetry && (strings.Contains(err.Error(), "profile") ||
-			strings.Contains(err.Error(), "not found") ||
-			strings.Contains(err.Error(), "does not exist")) {
+		classified := classifyError(err)
+		if !isRetry && classified.StaleProfile {
 			fmt.Println("Profile may be stale, refreshing...")
-			return streamToClaudeWithRetry(ctx, turns, callback, true)
+			return streamToClaudeWithRetry(ctx, model, turns, callback, thinking, restart, true)
 		}
+		reportRequestID(err, nil)
+		return 0, classified
+	}
 
-		// Provide helpful error messages
-		errStr := err.Error()
-		if strings.Contains(errStr, "Extra inputs") {
-			return 0, fmt.Errorf("this model doesn't support the configured features. Try disabling thinking: ask cfg thinking off")
+	// Read the response, requesting it again if the connection drops
+	for attempt := 1; ; attempt++ {
+		logRequestID(output.ResultMetadata)
+
+		var usage types.TokenUsage
+		totalTokens, delivered, err := readStream(ctx, output, callback, thinking, &usage)
+		if err != nil && err != context.Canceled {
+			reportRequestID(err, &output.ResultMetadata)
+		}
+		var blocked *GuardrailError
+		if errors.As(err, &blocked) {
+			blocked.ID = cfg.Guardrail.ID
 		}
-		if strings.Contains(errStr, "thinking") || strings.Contains(errStr, "budget_tokens") {
-			return 0, fmt.Errorf("thinking configuration error. Try disabling with: ask cfg thinking off\nError: %w", err)
+		if err == nil {
+			analytics.Record(modelID, int(aws.ToInt32(usage.InputTokens)), int(aws.ToInt32(usage.OutputTokens)))
+		}
+		if err == nil || !isConnectionError(err) || attempt >= cfg.RetryMaxAttempts {
+			return totalTokens, err
+		}
+
+		// Without restart the caller can't take back what it already received
+		if delivered && restart == nil {
+			return totalTokens, err
 		}
-		if strings.Contains(errStr, "inference profile") {
-			return 0, fmt.Errorf("model requires additional setup. Try: ask cfg model opus")
+
+		fmt.Fprintf(os.Stderr, "\nConnection lost (%v), retrying (%d/%d)...\n", err, attempt+1, cfg.RetryMaxAttempts)
+		if delivered {
+			if err := restart(); err != nil {
+				return 0, fmt.Errorf("failed to discard partial response: %w", err)
+			}
 		}
-		if strings.Contains(errStr, "context-1m") {
-			return 0, fmt.Errorf("1M context window requires tier 4 access. Remove 'enable_1m_context' from config")
+
+		if output, err = client.ConverseStream(ctx, input); err != nil {
+			reportRequestID(err, nil)
+			return 0, fmt.Errorf("failed to reconnect to Claude: %w", err)
 		}
-		return 0, fmt.Errorf("failed to invoke Claude: %w", err)
 	}
+}
 
+// readStream processes events until the response ends, reporting whether
+// any chunk reached callback or thinking. Token usage is stored in usage.
+// A response stopped by a guardrail returns a GuardrailError without an ID.
+func readStream(ctx context.Context, output *bedrockruntime.ConverseStreamOutput, callback StreamCallback, thinking ThinkingCallback, usage *types.TokenUsage) (int, bool, error) {
 	// Get the event stream
 	eventStream := output.GetStream()
 	defer eventStream.Close()
 
 	// Process the stream
 	totalTokens := 0
+	delivered := false
+	intervened := false
+	var trace *types.GuardrailTraceAssessment
 	for {
 		select {
 		case <-ctx.Done():
 			// Context cancelled (e.g., Ctrl+C)
-			return totalTokens, context.Canceled
+			return totalTokens, delivered, context.Canceled
 		default:
 			event, ok := <-eventStream.Events()
 			if !ok {
-				// Stream ended
-				return totalTokens, nil
+				// Stream ended, possibly because the connection dropped
+				if err := eventStream.Err(); err != nil {
+					return totalTokens, delivered, err
+				}
+				if intervened {
+					return totalTokens, delivered, &GuardrailError{Topic: guardrailTopic(trace)}
+				}
+				return totalTokens, delivered, nil
 			}
 
 			switch v := event.(type) {
@@ -172,20 +219,32 @@ func streamToClaudeWithRetry(ctx context.Context, turns []session.Turn, callback
 						if chunk != "" {
 							tokens := len(chunk) / 4 // Approximate
 							totalTokens += tokens
+							delivered = true
 							if err := callback(chunk, totalTokens); err != nil {
-								return totalTokens, err
+								return totalTokens, delivered, err
+							}
+						}
+					case *types.ContentBlockDeltaMemberReasoningContent:
+						if text, ok := delta.Value.(*types.ReasoningContentBlockDeltaMemberText); ok && thinking != nil {
+							delivered = true
+							if err := thinking(text.Value); err != nil {
+								return totalTokens, delivered, err
 							}
 						}
 					}
 				}
 
 			case *types.ConverseStreamOutputMemberMessageStop:
-				// End of message
-				return totalTokens, nil
+				// End of message; usage metadata follows before the stream closes
+				intervened = v.Value.StopReason == types.StopReasonGuardrailIntervened
 
 			case *types.ConverseStreamOutputMemberMetadata:
-				// Metadata about usage - could extract token counts here
+				// Metadata about usage
+				if v.Value.Trace != nil {
+					trace = v.Value.Trace.Guardrail
+				}
 				if v.Value.Usage != nil {
+					*usage = *v.Value.Usage
 					if v.Value.Usage.OutputTokens != nil {
 						totalTokens = int(*v.Value.Usage.OutputTokens)
 					}
@@ -194,3 +253,40 @@ func streamToClaudeWithRetry(ctx context.Context, turns []session.Turn, callback
 		}
 	}
 }
+
+// buildMessages converts session turns to Converse messages. Consecutive
+// turns with the same role, such as injected context before the first
+// question, become one message since Converse requires roles to alternate.
+func buildMessages(turns []session.Turn) []types.Message {
+	var messages []types.Message
+	for _, turn := range turns {
+		var role types.ConversationRole
+		if turn.Role == "Human" {
+			role = types.ConversationRoleUser
+		} else {
+			role = types.ConversationRoleAssistant
+		}
+
+		block := &types.ContentBlockMemberText{Value: turn.Content}
+		if n := len(messages); n > 0 && messages[n-1].Role == role {
+			messages[n-1].Content = append(messages[n-1].Content, block)
+			continue
+		}
+
+		messages = append(messages, types.Message{
+			Role:    role,
+			Content: []types.ContentBlock{block},
+		})
+	}
+	return messages
+}
+
+// isConnectionError reports whether err is a dropped or timed out connection,
+// which is worth retrying, rather than an error from Bedrock itself
+func isConnectionError(err error) bool {
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,47 @@
+package bedrock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rana/ask/internal/config"
+)
+
+// WarmupResult is how long each step of warming up one model took
+type WarmupResult struct {
+	Model      string
+	ModelID    string
+	ProfileARN string
+	Discovery  time.Duration // Finding or creating the inference profile, usually 0 when cached
+	Invocation time.Duration // The one-token test request
+}
+
+// Warmup discovers and caches the inference profile for model, then sends a
+// one-token "Ready" to check it can be invoked. The result holds whatever
+// steps finished before an error.
+func Warmup(ctx context.Context, model string) (WarmupResult, error) {
+	result := WarmupResult{Model: model}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return result, fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.Model = model
+
+	if result.ModelID, err = cfg.ResolveModel(); err != nil {
+		return result, fmt.Errorf("failed to resolve model: %w", err)
+	}
+
+	start := time.Now()
+	result.ProfileARN, _, err = ensureProfile(result.ModelID)
+	result.Discovery = time.Since(start)
+	if err != nil {
+		return result, fmt.Errorf("failed to setup model: %w", err)
+	}
+
+	start = time.Now()
+	err = invokeOneToken(ctx, cfg, result.ProfileARN, "Ready")
+	result.Invocation = time.Since(start)
+	return result, err
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ModelAutoSelect picks the model from the estimated size of the prompt
+type ModelAutoSelect struct {
+	Enabled    bool             `toml:"enabled"`
+	Thresholds []ModelThreshold `toml:"thresholds,omitempty"`
+}
+
+// ModelThreshold uses Model for prompts of at least MinTokens tokens
+type ModelThreshold struct {
+	MinTokens int    `toml:"min_tokens"`
+	Model     string `toml:"model"`
+}
+
+// DefaultThresholds uses haiku below 2,000 tokens, sonnet up to 10,000 and opus above
+func DefaultThresholds() []ModelThreshold {
+	return []ModelThreshold{
+		{MinTokens: 0, Model: "haiku"},
+		{MinTokens: 2000, Model: "sonnet"},
+		{MinTokens: 10000, Model: "opus"},
+	}
+}
+
+// Select returns the threshold with the largest MinTokens not above tokens,
+// and the MinTokens of the one after it, or 0 when it is the last.
+// It reports false when no threshold applies.
+func (a ModelAutoSelect) Select(tokens int) (ModelThreshold, int, bool) {
+	thresholds := append([]ModelThreshold(nil), a.Thresholds...)
+	sort.SliceStable(thresholds, func(i, j int) bool {
+		return thresholds[i].MinTokens < thresholds[j].MinTokens
+	})
+
+	selected := -1
+	for i, t := range thresholds {
+		if t.MinTokens > tokens {
+			break
+		}
+		selected = i
+	}
+	if selected == -1 {
+		return ModelThreshold{}, 0, false
+	}
+
+	next := 0
+	if selected+1 < len(thresholds) {
+		next = thresholds[selected+1].MinTokens
+	}
+	return thresholds[selected], next, true
+}
+
+// validate returns a problem for each threshold Select can't use
+func (a ModelAutoSelect) validate() []string {
+	var problems []string
+	if a.Enabled && len(a.Thresholds) == 0 {
+		problems = append(problems, "model_auto_select is enabled without thresholds")
+	}
+	seen := make(map[int]bool)
+	for _, t := range a.Thresholds {
+		switch {
+		case t.Model == "":
+			problems = append(problems, fmt.Sprintf("model_auto_select threshold %d has no model", t.MinTokens))
+		case t.MinTokens < 0:
+			problems = append(problems, fmt.Sprintf("model_auto_select threshold %d must not be negative", t.MinTokens))
+		case seen[t.MinTokens]:
+			problems = append(problems, fmt.Sprintf("model_auto_select threshold %d is listed twice", t.MinTokens))
+		}
+		seen[t.MinTokens] = true
+	}
+	return problems
+}
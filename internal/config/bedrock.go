@@ -0,0 +1,242 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Bedrock holds request settings passed to the Bedrock runtime
+type Bedrock struct {
+	HTTPHeaders map[string]string `toml:"http_headers,omitempty"`
+	Extra       BedrockExtra      `toml:"extra"`
+}
+
+// BedrockExtra holds inference parameters beyond temperature and max tokens
+type BedrockExtra struct {
+	TopK          int                    `toml:"top_k,omitzero"`
+	TopPNucleus   float64                `toml:"top_p,omitzero"`
+	StopSequences []string               `toml:"stop_sequences,omitempty"`
+	Raw           map[string]interface{} `toml:"raw,omitempty"` // Sent as-is in additional model request fields
+}
+
+// Typed [bedrock.extra] keys; anything else is stored in Raw
+const (
+	ExtraTopK          = "top_k"
+	ExtraTopP          = "top_p"
+	ExtraStopSequences = "stop_sequences"
+)
+
+// Set parses value for key, storing unknown keys in Raw
+func (e *BedrockExtra) Set(key, value string) error {
+	switch key {
+	case ExtraTopK:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("top_k must be a positive integer")
+		}
+		e.TopK = n
+	case ExtraTopP:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f <= 0 || f > 1 {
+			return fmt.Errorf("top_p must be between 0.0 and 1.0")
+		}
+		e.TopPNucleus = f
+	case ExtraStopSequences:
+		var sequences []string
+		for _, seq := range strings.Split(value, ",") {
+			if seq = strings.TrimSpace(seq); seq != "" {
+				sequences = append(sequences, seq)
+			}
+		}
+		if len(sequences) == 0 {
+			return fmt.Errorf("stop_sequences needs at least one comma-separated value")
+		}
+		e.StopSequences = sequences
+	default:
+		if e.Raw == nil {
+			e.Raw = make(map[string]interface{})
+		}
+		e.Raw[key] = parseRawValue(value)
+	}
+	return nil
+}
+
+// Get returns the value stored for key
+func (e *BedrockExtra) Get(key string) (interface{}, bool) {
+	switch key {
+	case ExtraTopK:
+		return e.TopK, e.TopK != 0
+	case ExtraTopP:
+		return e.TopPNucleus, e.TopPNucleus != 0
+	case ExtraStopSequences:
+		return e.StopSequences, len(e.StopSequences) > 0
+	}
+	value, ok := e.Raw[key]
+	return value, ok
+}
+
+// Keys returns the keys that are set, typed keys first then Raw keys sorted
+func (e *BedrockExtra) Keys() []string {
+	var keys []string
+	for _, key := range []string{ExtraTopK, ExtraTopP, ExtraStopSequences} {
+		if _, ok := e.Get(key); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	var raw []string
+	for key := range e.Raw {
+		switch key {
+		case ExtraTopK, ExtraTopP, ExtraStopSequences:
+			// Shadowed by the typed field
+		default:
+			raw = append(raw, key)
+		}
+	}
+	sort.Strings(raw)
+
+	return append(keys, raw...)
+}
+
+// Clear removes key, or every setting when key is empty
+func (e *BedrockExtra) Clear(key string) bool {
+	if key == "" {
+		had := len(e.Keys()) > 0
+		*e = BedrockExtra{}
+		return had
+	}
+
+	if _, ok := e.Get(key); !ok {
+		return false
+	}
+
+	switch key {
+	case ExtraTopK:
+		e.TopK = 0
+	case ExtraTopP:
+		e.TopPNucleus = 0
+	case ExtraStopSequences:
+		e.StopSequences = nil
+	default:
+		delete(e.Raw, key)
+	}
+	return true
+}
+
+// parseRawValue stores numbers and booleans with their natural TOML types
+func parseRawValue(value string) interface{} {
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	return value
+}
+
+// HTTPHeaders returns custom headers from the [bedrock.http_headers] table
+func (c *Config) HTTPHeaders() map[string]string {
+	headers := make(map[string]string, len(c.Bedrock.HTTPHeaders))
+	for name, value := range c.Bedrock.HTTPHeaders {
+		headers[name] = value
+	}
+	return headers
+}
+
+// SetHTTPHeader adds or replaces a custom Bedrock request header
+func (c *Config) SetHTTPHeader(name, value string) {
+	if c.Bedrock.HTTPHeaders == nil {
+		c.Bedrock.HTTPHeaders = make(map[string]string)
+	}
+	c.Bedrock.HTTPHeaders[name] = value
+}
+
+// RemoveHTTPHeader deletes a custom Bedrock request header
+func (c *Config) RemoveHTTPHeader(name string) bool {
+	if _, ok := c.Bedrock.HTTPHeaders[name]; !ok {
+		return false
+	}
+	delete(c.Bedrock.HTTPHeaders, name)
+	return true
+}
+
+// migrateBedrock moves keys from the old free-form [bedrock] table into
+// [bedrock.extra.raw], reporting whether anything changed
+func migrateBedrock(path string, meta toml.MetaData, cfg *Config) bool {
+	legacy := false
+	for _, key := range meta.Undecoded() {
+		if len(key) == 2 && key[0] == "bedrock" {
+			legacy = true
+			break
+		}
+	}
+	if !legacy {
+		return false
+	}
+
+	var old struct {
+		Bedrock map[string]interface{} `toml:"bedrock"`
+	}
+	if _, err := toml.DecodeFile(path, &old); err != nil {
+		return false
+	}
+
+	for key, value := range old.Bedrock {
+		switch key {
+		case "http_headers", "extra":
+			continue
+		case "thinking", "enable_1m_context":
+			// Never sent by older versions either
+			continue
+		}
+		if migrateTypedExtra(&cfg.Bedrock.Extra, key, value) {
+			continue
+		}
+		if cfg.Bedrock.Extra.Raw == nil {
+			cfg.Bedrock.Extra.Raw = make(map[string]interface{})
+		}
+		cfg.Bedrock.Extra.Raw[key] = value
+	}
+
+	return true
+}
+
+// migrateTypedExtra moves a legacy top_k, top_p or stop_sequences value into
+// its typed field, reporting whether it did
+func migrateTypedExtra(extra *BedrockExtra, key string, value interface{}) bool {
+	switch key {
+	case ExtraTopK:
+		if n, ok := value.(int64); ok {
+			extra.TopK = int(n)
+			return true
+		}
+	case ExtraTopP:
+		if f, ok := value.(float64); ok {
+			extra.TopPNucleus = f
+			return true
+		}
+	case ExtraStopSequences:
+		items, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		var sequences []string
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return false
+			}
+			sequences = append(sequences, s)
+		}
+		extra.StopSequences = sequences
+		return true
+	}
+	return false
+}
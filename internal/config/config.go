@@ -4,34 +4,139 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
+// CurrentVersion is the config layout this version of ask writes
+const CurrentVersion = 1
+
 type Config struct {
-	Version     int                    `toml:"version"`
-	Model       string                 `toml:"model"`
-	Temperature float64                `toml:"temperature"`
-	MaxTokens   int                    `toml:"max_tokens"`
-	Timeout     string                 `toml:"timeout"`
-	Context     string                 `toml:"context"`
-	Thinking    Thinking               `toml:"thinking"`
-	Expand      Expand                 `toml:"expand"`
-	Filter      Filter                 `toml:"filter"`
-	Bedrock     map[string]interface{} `toml:"bedrock,omitempty"`
+	Version     int      `toml:"version"`
+	Model       string   `toml:"model"`
+	Temperature float64  `toml:"temperature"`
+	MaxTokens   int      `toml:"max_tokens"`
+	Timeout     string   `toml:"timeout"`
+	Context     string   `toml:"context"`
+	Proxy       string   `toml:"proxy,omitempty"`
+	Thinking    Thinking `toml:"thinking"`
+	Expand      Expand   `toml:"expand"`
+	Filter      Filter   `toml:"filter"`
+	Bedrock     Bedrock  `toml:"bedrock"`
+	Session     Session  `toml:"session"`
+
+	// RetryMaxAttempts bounds how often a dropped response stream is requested
+	RetryMaxAttempts int `toml:"retry_max_attempts"`
+	// FallbackModels are tried in order when the model is out of capacity
+	FallbackModels []string `toml:"fallback_models,omitempty"`
+	// ProvisionedARN is a provisioned throughput model used instead of inference profiles
+	ProvisionedARN string `toml:"provisioned_arn,omitempty"`
+	// ProfileFilter limits profile discovery to ARNs containing it, e.g. "us-east-1"
+	ProfileFilter string `toml:"profile_filter,omitempty"`
+	// Guardrail applies a Bedrock guardrail to requests and responses
+	Guardrail Guardrail `toml:"guardrail,omitempty"`
+	// ModelAutoSelect picks the model from the size of the prompt, see autoselect.go
+	ModelAutoSelect ModelAutoSelect `toml:"model_auto_select,omitempty"`
+	// Memory recalls passages of earlier sessions into ask chat
+	Memory Memory `toml:"memory,omitempty"`
+	// Hooks are shell commands ask chat runs around each request
+	Hooks Hooks `toml:"hooks,omitempty"`
+	// Streaming controls how often streamed responses are written to the session
+	Streaming Streaming `toml:"streaming"`
+
+	overlay string // Name of the overlay applied on load, see LoadWithOverlay
+}
+
+// Session controls how session files are named
+type Session struct {
+	Extension      string `toml:"extension"`        // e.g. ".md", ".txt", ".adoc"
+	UsePartialFile bool   `toml:"use_partial_file"` // Mirror streamed chunks to session.partial.md
+	StaleAfter     string `toml:"stale_after"`      // Age at which ask init offers to archive, e.g. "7d"
+	Timing         bool   `toml:"timing"`           // Timestamp AI headers and record response durations
+	AutoInit       bool   `toml:"auto_init"`        // ask chat creates a missing session instead of failing
+	Format         string `toml:"format"`           // Session layout: ask-md, frontmatter-md or numbered-md
+	Dir            string `toml:"dir"`              // Where the default session file lives, "." for the working directory
+
+	// InitTemplate replaces the "# [1] Human" that ask init writes; see init.go for placeholders
+	InitTemplate string `toml:"init_template,omitempty"`
+	// MaxSizeBytes makes ask chat refuse larger sessions; 0 means no limit
+	MaxSizeBytes int64 `toml:"max_size_bytes,omitzero"`
+	// FenceDepth is how many backticks wrap AI responses; a response
+	// containing a fence that long gets a longer one
+	FenceDepth int `toml:"fence_depth"`
+}
+
+// Memory selects the backend that recalls earlier sessions
+type Memory struct {
+	Enabled bool   `toml:"enabled"`
+	Backend string `toml:"backend,omitempty"` // "local": a TF-IDF index in ~/.ask/memory
+	TopK    int    `toml:"top_k,omitzero"`    // Passages recalled per chat, 3 when 0
+}
+
+// Limit returns how many passages to recall
+func (m Memory) Limit() int {
+	if m.TopK > 0 {
+		return m.TopK
+	}
+	return 3
+}
+
+// Streaming batches response chunks before writing them to the session file.
+// A batch is written once it reaches MaxBufferKB or WriteInterval has passed
+// since the last write, checked as chunks arrive.
+type Streaming struct {
+	MaxBufferKB   int    `toml:"max_buffer_kb"`
+	WriteInterval string `toml:"write_interval"` // e.g. "250ms"; 0 writes every chunk
+}
+
+// Interval returns WriteInterval as a duration, 0 if it doesn't parse
+func (s Streaming) Interval() time.Duration {
+	d, err := time.ParseDuration(s.WriteInterval)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// Hooks are shell commands run by ask chat; empty ones are skipped
+type Hooks struct {
+	PreChat  string `toml:"pre_chat,omitempty"`  // Before the request; a non-zero exit aborts the chat
+	PostChat string `toml:"post_chat,omitempty"` // After the response is written
+}
+
+// Guardrail identifies a Bedrock guardrail; it is applied when both fields are set
+type Guardrail struct {
+	ID      string `toml:"id,omitempty"`
+	Version string `toml:"version,omitempty"`
+}
+
+// Enabled reports whether a guardrail is configured
+func (g Guardrail) Enabled() bool {
+	return g.ID != "" && g.Version != ""
 }
 
 type Thinking struct {
 	Enabled bool    `toml:"enabled"`
 	Budget  float64 `toml:"budget"`
+	// BudgetTokens is an absolute budget that takes precedence over Budget when non-zero
+	BudgetTokens int `toml:"budget_tokens,omitzero"`
 }
 
 type Expand struct {
-	MaxDepth  int         `toml:"max_depth"`
-	Recursive bool        `toml:"recursive"`
-	Include   IncludeSpec `toml:"include"`
-	Exclude   ExcludeSpec `toml:"exclude"`
+	MaxDepth              int         `toml:"max_depth"`
+	Recursive             bool        `toml:"recursive"`
+	GlobalSectionNumbers  bool        `toml:"global_section_numbers"`            // Number sections across the session, not per turn
+	Sort                  string      `toml:"sort"`                              // File order within a directory: name, mtime, size or type
+	DirectoryHeading      bool        `toml:"directory_heading"`                 // Nest directory files under a "src/ (8 files)" heading
+	SectionSeparator      string      `toml:"section_separator"`                 // Placed between files of a directory
+	AllowBinaryExtensions []string    `toml:"allow_binary_extensions,omitempty"` // Expanded even if they look binary, e.g. "json"
+	AllowUTF16            bool        `toml:"allow_utf16"`                       // Decode files with a UTF-16 byte order mark
+	Include               IncludeSpec `toml:"include"`
+	Exclude               ExcludeSpec `toml:"exclude"`
 }
 
 type IncludeSpec struct {
@@ -49,6 +154,14 @@ type Filter struct {
 	StripHeaders     bool         `toml:"strip_headers"`
 	StripAllComments bool         `toml:"strip_all_comments"`
 	Header           HeaderFilter `toml:"header"`
+	Rules            []FilterRule `toml:"rules,omitempty"` // Applied in order after the built-in stripping
+}
+
+// FilterRule replaces matches of a regular expression in expanded files
+type FilterRule struct {
+	Pattern     string `toml:"pattern"`
+	Replacement string `toml:"replacement"`
+	Scope       string `toml:"scope"` // "line" matches each line; "block" matches the whole file
 }
 
 type HeaderFilter struct {
@@ -63,7 +176,7 @@ type HeaderPattern struct {
 
 func Defaults() *Config {
 	return &Config{
-		Version:     1,
+		Version:     CurrentVersion,
 		Model:       "opus",
 		Temperature: 1.0,
 		MaxTokens:   32000,
@@ -76,6 +189,9 @@ func Defaults() *Config {
 		Expand: Expand{
 			MaxDepth:  3,
 			Recursive: false,
+			Sort:      "name",
+
+			SectionSeparator: "\n\n",
 			Include: IncludeSpec{
 				Extensions: []string{"go", "rs", "py", "js", "ts", "jsx", "tsx", "java", "cpp", "c", "h", "hpp", "cs", "rb", "php", "swift", "kt", "scala", "sh", "bash", "zsh", "fish", "ps1", "md", "txt", "json", "yaml", "yml", "toml", "xml", "html", "css", "scss", "sass", "sql", "proto"},
 				Patterns:   []string{"Makefile", "Dockerfile", ".gitignore", ".env.example", "README", "LICENSE"},
@@ -115,11 +231,35 @@ func Defaults() *Config {
 				},
 			},
 		},
-		Bedrock: make(map[string]interface{}),
+		Session: Session{
+			Extension:  ".md",
+			StaleAfter: "7d",
+			Format:     "ask-md",
+			Dir:        ".",
+			FenceDepth: 4,
+		},
+		Streaming: Streaming{
+			MaxBufferKB:   4,
+			WriteInterval: "250ms",
+		},
+		RetryMaxAttempts: 3,
 	}
 }
 
+// Load reads ~/.ask/cfg.toml and applies the overlay named by ASK_OVERLAY, if any
 func Load() (*Config, error) {
+	return LoadWithOverlay(os.Getenv("ASK_OVERLAY"))
+}
+
+// loadMu serializes loads, since a load may save migrated defaults and
+// parallel batch runs load the config at once
+var loadMu sync.Mutex
+
+// loadBase reads ~/.ask/cfg.toml, filling in and saving missing defaults
+func loadBase() (*Config, error) {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
 	path := ConfigPath()
 
 	// Create default config if it doesn't exist
@@ -132,17 +272,17 @@ func Load() (*Config, error) {
 	}
 
 	cfg := &Config{}
-	_, err := toml.DecodeFile(path, cfg)
+	meta, err := toml.DecodeFile(path, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode config: %w", err)
 	}
 
 	// Apply defaults for any missing fields
-	needsUpdate := false
+	needsUpdate := migrateBedrock(path, meta, cfg)
 
 	// Version migration
 	if cfg.Version == 0 {
-		cfg.Version = 1
+		cfg.Version = CurrentVersion
 		needsUpdate = true
 	}
 	if cfg.Temperature == 0 {
@@ -157,12 +297,43 @@ func Load() (*Config, error) {
 		cfg.Timeout = "5m"
 		needsUpdate = true
 	}
+	if cfg.RetryMaxAttempts == 0 {
+		cfg.RetryMaxAttempts = 3
+		needsUpdate = true
+	}
 	if cfg.Thinking.Budget == 0 {
 		cfg.Thinking.Budget = 0.8
 		needsUpdate = true
 	}
-	if cfg.Bedrock == nil {
-		cfg.Bedrock = make(map[string]interface{})
+
+	if cfg.Session.Extension == "" {
+		cfg.Session.Extension = ".md"
+		needsUpdate = true
+	}
+	if cfg.Session.StaleAfter == "" {
+		cfg.Session.StaleAfter = "7d"
+		needsUpdate = true
+	}
+	if cfg.Session.Format == "" {
+		cfg.Session.Format = "ask-md"
+		needsUpdate = true
+	}
+	if cfg.Session.Dir == "" {
+		cfg.Session.Dir = "."
+		needsUpdate = true
+	}
+	if cfg.Session.FenceDepth == 0 {
+		cfg.Session.FenceDepth = 4
+		needsUpdate = true
+	}
+	// 0 is a valid buffer size (write every chunk), so only a missing key defaults
+	if !meta.IsDefined("streaming", "max_buffer_kb") {
+		cfg.Streaming.MaxBufferKB = 4
+		needsUpdate = true
+	}
+	if cfg.Streaming.WriteInterval == "" {
+		cfg.Streaming.WriteInterval = "250ms"
+		needsUpdate = true
 	}
 
 	// Expand defaults
@@ -170,6 +341,14 @@ func Load() (*Config, error) {
 		cfg.Expand.MaxDepth = 3
 		needsUpdate = true
 	}
+	if cfg.Expand.Sort == "" {
+		cfg.Expand.Sort = "name"
+		needsUpdate = true
+	}
+	if cfg.Expand.SectionSeparator == "" {
+		cfg.Expand.SectionSeparator = "\n\n"
+		needsUpdate = true
+	}
 	if len(cfg.Expand.Include.Extensions) == 0 {
 		defaults := Defaults()
 		cfg.Expand.Include = defaults.Expand.Include
@@ -205,28 +384,73 @@ func Load() (*Config, error) {
 }
 
 func (c *Config) Save() error {
+	if c.overlay != "" {
+		return fmt.Errorf("overlay '%s' is active; unset ASK_OVERLAY to change the base config", c.overlay)
+	}
+
 	dir := filepath.Dir(ConfigPath())
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	// Write beside the config and only replace it once the result reads back
+	// and validates, so a failed save leaves the previous config in place
+	// The temporary name is unique so concurrent saves never share it
 	path := ConfigPath()
-	file, err := os.Create(path)
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
 	if err != nil {
 		return fmt.Errorf("failed to create config file: %w", err)
 	}
-	defer file.Close()
+	tmp := file.Name()
+	if err := file.Chmod(0644); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
 
-	encoder := toml.NewEncoder(file)
-	encoder.Indent = ""
-	if err := encoder.Encode(c); err != nil {
+	err = c.Encode(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
 		return fmt.Errorf("failed to encode config: %w", err)
 	}
 
+	var written Config
+	if _, err := toml.DecodeFile(tmp, &written); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("config did not read back, keeping the previous one: %w", err)
+	}
+	if err := written.Validate(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%w; keeping the previous config", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace config file: %w", err)
+	}
 	return nil
 }
 
+// configPath overrides the config location when set, see SetConfigPath
+var configPath string
+
+// SetConfigPath makes ConfigPath return path, e.g. from the --config flag
+func SetConfigPath(path string) {
+	configPath = path
+}
+
+// ConfigPath returns the config file: the --config flag, then ASK_CONFIG,
+// then ~/.ask/cfg.toml
 func ConfigPath() string {
+	if configPath != "" {
+		return configPath
+	}
+	if env := os.Getenv("ASK_CONFIG"); env != "" {
+		return env
+	}
 	return filepath.Join(os.Getenv("HOME"), ".ask", "cfg.toml")
 }
 
@@ -234,14 +458,99 @@ func CachePath() string {
 	return filepath.Join(os.Getenv("HOME"), ".ask", "cache")
 }
 
+func ArchivePath() string {
+	return filepath.Join(os.Getenv("HOME"), ".ask", "archive")
+}
+
+func GlobalSessionPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".ask", "global", SessionFileName())
+}
+
+// SessionFileName returns "session" with the configured extension
+func SessionFileName() string {
+	cfg, err := Load()
+	if err != nil || cfg.Session.Extension == "" {
+		return "session.md"
+	}
+	return "session" + cfg.Session.Extension
+}
+
+// SessionDir returns the directory holding the default session file, with a
+// leading ~ expanded; "." means the working directory
+func SessionDir() string {
+	cfg, err := Load()
+	if err != nil || cfg.Session.Dir == "" {
+		return "."
+	}
+	if dir, ok := strings.CutPrefix(cfg.Session.Dir, "~/"); ok {
+		return filepath.Join(os.Getenv("HOME"), dir)
+	}
+	return cfg.Session.Dir
+}
+
+func ExecLogPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".ask", "exec.log")
+}
+
+// UsagePath returns the token usage log, one JSON entry per response
+func UsagePath() string {
+	return filepath.Join(os.Getenv("HOME"), ".ask", "usage.jsonl")
+}
+
+// TemplatesPath returns the directory of named templates for ask init --template
+func TemplatesPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".ask", "templates")
+}
+
+// MemoryPath returns the directory of the local memory index
+func MemoryPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".ask", "memory")
+}
+
+// BranchSessionsPath returns where ask branch-session keeps each branch's session
+func BranchSessionsPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".ask", "sessions")
+}
+
+// TagIndexPath returns the index mapping tags to sessions and turns
+func TagIndexPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".ask", "index.toml")
+}
+
+// SessionIndexPath returns the index ask search queries
+func SessionIndexPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".ask", "sessions.toml")
+}
+
 func (c *Config) ParseTimeout() (time.Duration, error) {
 	return time.ParseDuration(c.Timeout)
 }
 
+// ParseAge parses a duration that may also use days, e.g. "7d" or "36h"
+func ParseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age '%s'", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
 func (c *Config) GetThinkingTokens() int {
 	if !c.Thinking.Enabled {
 		return 0
 	}
+	return c.ThinkingBudgetTokens()
+}
+
+// ThinkingBudgetTokens returns the budget in tokens whether or not thinking
+// is enabled, capped below MaxTokens
+func (c *Config) ThinkingBudgetTokens() int {
+	if c.Thinking.BudgetTokens > 0 {
+		return min(c.Thinking.BudgetTokens, c.MaxTokens-1)
+	}
 	return int(float64(c.MaxTokens) * c.Thinking.Budget)
 }
 
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Change is a single setting that differs between two configs
+type Change struct {
+	Key string // Dotted TOML key, e.g. "expand.max_depth"
+	Old string
+	New string
+}
+
+// Diff compares two configs field by field and returns the differences.
+// Nested structs are walked; slices and maps are compared as whole values.
+func Diff(old, new *Config) []Change {
+	var changes []Change
+	diffValues("", reflect.ValueOf(*old), reflect.ValueOf(*new), &changes)
+	return changes
+}
+
+func diffValues(prefix string, old, new reflect.Value, changes *[]Change) {
+	t := old.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("toml"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		oldField := old.Field(i)
+		newField := new.Field(i)
+
+		if field.Type.Kind() == reflect.Struct {
+			diffValues(key, oldField, newField, changes)
+			continue
+		}
+
+		if reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			continue
+		}
+
+		// Treat nil and empty collections as equal
+		if isEmptyCollection(oldField) && isEmptyCollection(newField) {
+			continue
+		}
+
+		*changes = append(*changes, Change{
+			Key: key,
+			Old: formatValue(oldField),
+			New: formatValue(newField),
+		})
+	}
+}
+
+func isEmptyCollection(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	case reflect.Ptr:
+		if v.IsNil() {
+			return "unset"
+		}
+		return formatValue(v.Elem())
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
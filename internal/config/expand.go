@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// LocalExpand is a project-level .ask/expand.toml
+type LocalExpand struct {
+	// Extends "global" merges with ~/.ask/cfg.toml rules; otherwise local lists replace them
+	Extends   string      `toml:"extends"`
+	MaxDepth  int         `toml:"max_depth"`
+	Recursive *bool       `toml:"recursive"`
+	Sort      string      `toml:"sort"`
+	Include   IncludeSpec `toml:"include"`
+	Exclude   ExcludeSpec `toml:"exclude"`
+	Remove    RemoveSpec  `toml:"remove"`
+}
+
+// RemoveSpec lists inherited entries to drop when extending global rules
+type RemoveSpec struct {
+	Include IncludeSpec `toml:"include"`
+	Exclude ExcludeSpec `toml:"exclude"`
+}
+
+// LoadExpandConfig returns the global expansion rules merged with the
+// nearest .ask/expand.toml between the current directory and $HOME
+func LoadExpandConfig() (*Expand, error) {
+	cfg, err := Load()
+	if err != nil {
+		cfg = Defaults()
+	}
+	expand := cfg.Expand
+
+	path := FindLocalExpandConfig()
+	if path == "" {
+		return &expand, nil
+	}
+
+	var local LocalExpand
+	if _, err := toml.DecodeFile(path, &local); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	merged := mergeExpand(expand, local)
+	return &merged, nil
+}
+
+// FindLocalExpandConfig walks up from the current directory looking for
+// .ask/expand.toml, stopping below $HOME where the global config lives
+func FindLocalExpandConfig() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	home := os.Getenv("HOME")
+
+	for dir != home {
+		path := filepath.Join(dir, ".ask", "expand.toml")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return ""
+}
+
+// DirectoryExpandFile is the name of a per-directory rules file
+const DirectoryExpandFile = "expand.toml"
+
+// LoadDirectoryExpand applies dir/expand.toml, if present, to the inherited
+// rules for that directory and its children. Lists it sets replace the
+// inherited ones rather than adding to them, unless it extends "global".
+func LoadDirectoryExpand(dir string, inherited *Expand) (*Expand, error) {
+	path := filepath.Join(dir, DirectoryExpandFile)
+	if _, err := os.Stat(path); err != nil {
+		return inherited, nil
+	}
+
+	var local LocalExpand
+	if _, err := toml.DecodeFile(path, &local); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	if local.Extends == "global" {
+		merged := mergeExpand(*inherited, local)
+		return &merged, nil
+	}
+
+	merged := *inherited
+	applySettings(&merged, local)
+	replaceLists(&merged, local)
+	return &merged, nil
+}
+
+// replaceLists replaces each include and exclude list that local sets
+func replaceLists(merged *Expand, local LocalExpand) {
+	replaceList(&merged.Include.Extensions, local.Include.Extensions)
+	replaceList(&merged.Include.Patterns, local.Include.Patterns)
+	replaceList(&merged.Exclude.Patterns, local.Exclude.Patterns)
+	replaceList(&merged.Exclude.Directories, local.Exclude.Directories)
+}
+
+// replaceList sets *list to local when local was given
+func replaceList(list *[]string, local []string) {
+	if local != nil {
+		*list = local
+	}
+}
+
+// mergeExpand applies local rules on top of global ones
+func mergeExpand(global Expand, local LocalExpand) Expand {
+	merged := global
+	applySettings(&merged, local)
+
+	if local.Extends == "global" {
+		merged.Include.Extensions = mergeList(global.Include.Extensions, local.Include.Extensions, local.Remove.Include.Extensions)
+		merged.Include.Patterns = mergeList(global.Include.Patterns, local.Include.Patterns, local.Remove.Include.Patterns)
+		merged.Exclude.Patterns = mergeList(global.Exclude.Patterns, local.Exclude.Patterns, local.Remove.Exclude.Patterns)
+		merged.Exclude.Directories = mergeList(global.Exclude.Directories, local.Exclude.Directories, local.Remove.Exclude.Directories)
+		return merged
+	}
+
+	// Without extends, each list the local file sets replaces the global one
+	replaceLists(&merged, local)
+	return merged
+}
+
+// applySettings copies the non-list settings that local sets
+func applySettings(merged *Expand, local LocalExpand) {
+	if local.MaxDepth != 0 {
+		merged.MaxDepth = local.MaxDepth
+	}
+	if local.Recursive != nil {
+		merged.Recursive = *local.Recursive
+	}
+	if local.Sort != "" {
+		merged.Sort = local.Sort
+	}
+}
+
+// WithOverrides returns a copy of the rules with one-off include and exclude
+// patterns applied. An included pattern is dropped from the excludes so the
+// command line wins over the config.
+func (e Expand) WithOverrides(include, exclude []string) Expand {
+	result := e
+	result.Include.Patterns = mergeList(e.Include.Patterns, include, nil)
+	result.Exclude.Patterns = mergeList(e.Exclude.Patterns, exclude, include)
+	return result
+}
+
+// mergeList returns base plus add, minus remove, without duplicates
+func mergeList(base, add, remove []string) []string {
+	removed := make(map[string]bool, len(remove))
+	for _, r := range remove {
+		removed[r] = true
+	}
+
+	seen := make(map[string]bool)
+	var result []string
+	for _, list := range [][]string{base, add} {
+		for _, item := range list {
+			if removed[item] || seen[item] {
+				continue
+			}
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
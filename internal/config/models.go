@@ -5,12 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/BurntSushi/toml"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrock"
 )
 
@@ -56,10 +56,41 @@ func GetModels() ([]ModelInfo, error) {
 	return models, nil
 }
 
-// SelectModel returns the full model ID for a given type or ID
+var pinnedVersionPattern = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// ParseModelSpec splits "opus@4" into type and pinned version.
+// Specs without "@" return an empty version.
+func ParseModelSpec(spec string) (modelType, version string, err error) {
+	modelType, version, pinned := strings.Cut(spec, "@")
+	if !pinned {
+		return spec, "", nil
+	}
+
+	modelType = strings.ToLower(modelType)
+	switch modelType {
+	case "opus", "sonnet", "haiku":
+	default:
+		return "", "", fmt.Errorf("version pinning requires opus, sonnet or haiku, got '%s'", modelType)
+	}
+
+	if !pinnedVersionPattern.MatchString(version) {
+		return "", "", fmt.Errorf("invalid version '%s' (expected e.g. opus@4 or sonnet@3.5)", version)
+	}
+
+	return modelType, version, nil
+}
+
+// SelectModel returns the full model ID for a given type or ID.
+// "type@version" picks the latest model of that type whose version is
+// version or starts with "version.".
 func SelectModel(typeOrID string) (string, error) {
+	searchType, pinnedVersion, err := ParseModelSpec(typeOrID)
+	if err != nil {
+		return "", err
+	}
+
 	// If it looks like a full model ID, use it directly
-	if strings.Contains(typeOrID, ".") || strings.Contains(typeOrID, ":") {
+	if pinnedVersion == "" && (strings.Contains(typeOrID, ".") || strings.Contains(typeOrID, ":")) {
 		return typeOrID, nil
 	}
 
@@ -69,14 +100,22 @@ func SelectModel(typeOrID string) (string, error) {
 	}
 
 	// Normalize the input
-	searchType := strings.ToLower(typeOrID)
+	searchType = strings.ToLower(searchType)
 
-	// Filter by type
+	// Filter by type and pinned version
 	var matches []ModelInfo
 	for _, m := range models {
-		if m.Type == searchType {
-			matches = append(matches, m)
+		if m.Type != searchType {
+			continue
 		}
+		if pinnedVersion != "" && m.Version != pinnedVersion && !strings.HasPrefix(m.Version, pinnedVersion+".") {
+			continue
+		}
+		matches = append(matches, m)
+	}
+
+	if len(matches) == 0 && pinnedVersion != "" {
+		return "", fmt.Errorf("no %s model found for version %s", searchType, pinnedVersion)
 	}
 
 	if len(matches) == 0 {
@@ -107,7 +146,7 @@ func SelectModel(typeOrID string) (string, error) {
 
 // queryBedrockModels queries AWS Bedrock for available models
 func queryBedrockModels() ([]ModelInfo, error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+	cfg, err := LoadAWSConfig(context.TODO())
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
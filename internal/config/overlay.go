@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// OverlaysPath returns the directory holding named config overlays
+func OverlaysPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".ask", "overlays")
+}
+
+// OverlayPath returns the file for a named overlay
+func OverlayPath(name string) string {
+	return filepath.Join(OverlaysPath(), name+".toml")
+}
+
+// LoadWithOverlay loads the base config and decodes the named overlay on top.
+// Only keys present in the overlay change; an empty name loads the base alone.
+// The result can't be saved, so overlay values never leak into cfg.toml.
+func LoadWithOverlay(name string) (*Config, error) {
+	cfg, err := loadBase()
+	if err != nil || name == "" {
+		return cfg, err
+	}
+
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid overlay name '%s'", name)
+	}
+
+	path := OverlayPath(name)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("overlay '%s' not found (%s)", name, path)
+		}
+		return nil, fmt.Errorf("failed to read overlay '%s': %w", name, err)
+	}
+
+	if _, err := toml.DecodeFile(path, cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode overlay '%s': %w", name, err)
+	}
+	cfg.overlay = name
+
+	return cfg, nil
+}
+
+// Overlay returns the name of the overlay applied to this config, if any
+func (c *Config) Overlay() string {
+	return c.overlay
+}
+
+// ListOverlays returns the names of available overlays
+func ListOverlays() ([]string, error) {
+	entries, err := os.ReadDir(OverlaysPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read overlays: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".toml" {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".toml"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Encode writes the config as TOML, the same way Save does
+func (c *Config) Encode(w io.Writer) error {
+	encoder := toml.NewEncoder(w)
+	encoder.Indent = ""
+	return encoder.Encode(c)
+}
@@ -0,0 +1,17 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// provisionedARN matches arn:aws:bedrock:us-east-1:123456789012:provisioned-model/abc123
+var provisionedARN = regexp.MustCompile(`^arn:aws[\w-]*:bedrock:[\w-]+:\d{12}:provisioned-model/[\w.-]+$`)
+
+// ValidateProvisionedARN checks that arn names a provisioned throughput model
+func ValidateProvisionedARN(arn string) error {
+	if !provisionedARN.MatchString(arn) {
+		return fmt.Errorf("'%s' is not a provisioned throughput ARN (arn:aws:bedrock:<region>:<account>:provisioned-model/<id>)", arn)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// ParseProxy validates a proxy URL
+func ParseProxy(raw string) (*url.URL, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxy URL must use http, https or socks5, got '%s'", raw)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("proxy URL has no host: '%s'", raw)
+	}
+
+	return parsed, nil
+}
+
+// EnvProxy returns the proxy set by HTTPS_PROXY or HTTP_PROXY, if any
+func EnvProxy() string {
+	for _, key := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// RedactProxy hides any password in a proxy URL for display
+func RedactProxy(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return parsed.Redacted()
+}
+
+// LoadAWSConfig loads the AWS SDK configuration, routing traffic through
+// the configured proxy. Without one, the SDK's default transport already
+// honours HTTP_PROXY and HTTPS_PROXY.
+func LoadAWSConfig(ctx context.Context) (aws.Config, error) {
+	cfg, err := Load()
+	if err != nil || cfg.Proxy == "" {
+		return awsconfig.LoadDefaultConfig(ctx)
+	}
+
+	proxyURL, err := ParseProxy(cfg.Proxy)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	client := awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		tr.Proxy = http.ProxyURL(proxyURL)
+	})
+
+	return awsconfig.LoadDefaultConfig(ctx, awsconfig.WithHTTPClient(client))
+}
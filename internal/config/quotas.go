@@ -0,0 +1,30 @@
+package config
+
+import "strings"
+
+// QuotaDefault is a Bedrock on-demand quota for one model type
+type QuotaDefault struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// DefaultQuotas are typical on-demand quotas of a new account in us-east-1,
+// shown when the Service Quotas API can't be reached. Actual values vary by
+// region, model version and account.
+var DefaultQuotas = map[string]QuotaDefault{
+	"opus":   {RequestsPerMinute: 50, TokensPerMinute: 400_000},
+	"sonnet": {RequestsPerMinute: 50, TokensPerMinute: 400_000},
+	"haiku":  {RequestsPerMinute: 1_000, TokensPerMinute: 2_000_000},
+}
+
+// ModelType returns "opus", "sonnet" or "haiku" for a model alias or ID, or
+// "" if it is none of them
+func ModelType(model string) string {
+	lower := strings.ToLower(model)
+	for _, t := range []string{"opus", "sonnet", "haiku"} {
+		if strings.Contains(lower, t) {
+			return t
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Validate checks settings for values the cfg commands would reject, so a
+// bad write can't leave a config that breaks every later Load
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, a ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, a...))
+	}
+
+	if c.Temperature < 0 || c.Temperature > 1 {
+		add("temperature %v must be between 0.0 and 1.0", c.Temperature)
+	}
+	if c.MaxTokens <= 0 {
+		add("max_tokens %d must be positive", c.MaxTokens)
+	}
+	if _, err := c.ParseTimeout(); err != nil {
+		add("timeout '%s' is not a duration", c.Timeout)
+	}
+	if c.Context != "" && c.Context != "standard" && c.Context != "1m" {
+		add("context '%s' must be standard or 1m", c.Context)
+	}
+	if c.Thinking.Budget <= 0 || c.Thinking.Budget > 1 {
+		add("thinking.budget %v must be between 0.0 and 1.0", c.Thinking.Budget)
+	}
+	if c.RetryMaxAttempts < 1 {
+		add("retry_max_attempts %d must be at least 1", c.RetryMaxAttempts)
+	}
+	if (c.Guardrail.ID == "") != (c.Guardrail.Version == "") {
+		add("guardrail needs both id and version")
+	}
+	problems = append(problems, c.ModelAutoSelect.validate()...)
+	if c.Memory.Backend != "" && c.Memory.Backend != "local" {
+		add("memory.backend '%s' must be local", c.Memory.Backend)
+	}
+	if c.Memory.TopK < 0 {
+		add("memory.top_k %d must not be negative", c.Memory.TopK)
+	}
+	if c.ProvisionedARN != "" {
+		if err := ValidateProvisionedARN(c.ProvisionedARN); err != nil {
+			add("provisioned_arn: %v", err)
+		}
+	}
+
+	if c.Expand.MaxDepth < 1 || c.Expand.MaxDepth > 10 {
+		add("expand.max_depth %d must be between 1 and 10", c.Expand.MaxDepth)
+	}
+	switch c.Expand.Sort {
+	case "", "name", "mtime", "size", "type":
+	default:
+		add("expand.sort '%s' must be name, mtime, size or type", c.Expand.Sort)
+	}
+
+	for _, rule := range c.Filter.Rules {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			add("filter rule '%s' is not a valid regular expression", rule.Pattern)
+		}
+	}
+
+	if !strings.HasPrefix(c.Session.Extension, ".") {
+		add("session.extension '%s' must start with a dot", c.Session.Extension)
+	}
+	if c.Session.FenceDepth != 0 && (c.Session.FenceDepth < 3 || c.Session.FenceDepth > 10) {
+		add("session.fence_depth %d must be between 3 and 10", c.Session.FenceDepth)
+	}
+	if c.Session.MaxSizeBytes < 0 {
+		add("session.max_size_bytes %d must not be negative", c.Session.MaxSizeBytes)
+	}
+	if _, err := ParseAge(c.Session.StaleAfter); err != nil {
+		add("session.stale_after '%s' is not an age like 7d", c.Session.StaleAfter)
+	}
+
+	if c.Streaming.MaxBufferKB < 0 || c.Streaming.MaxBufferKB > 1024 {
+		add("streaming.max_buffer_kb %d must be between 0 and 1024", c.Streaming.MaxBufferKB)
+	}
+	if c.Streaming.WriteInterval != "" {
+		if _, err := time.ParseDuration(c.Streaming.WriteInterval); err != nil {
+			add("streaming.write_interval '%s' is not a duration like 250ms", c.Streaming.WriteInterval)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package expand
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rana/ask/internal/config"
+)
+
+// DepsPrefix marks a [[deps:file.go]] reference
+const DepsPrefix = "deps:"
+
+// goPackage is the subset of `go list -json` output used for dependency expansion
+type goPackage struct {
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+	Imports    []string
+}
+
+// depsCache stores `go list` output for one module directory
+type depsCache struct {
+	CachedAt time.Time
+	Packages map[string]goPackage // Keyed by import path
+}
+
+// ParseDepsSpec splits "main.go --depth=2" into file and depth (default 1)
+func ParseDepsSpec(spec string) (string, int, error) {
+	fields := strings.Fields(strings.TrimPrefix(spec, DepsPrefix))
+	if len(fields) == 0 {
+		return "", 0, fmt.Errorf("deps reference needs a Go file")
+	}
+
+	depth := 1
+	for _, field := range fields[1:] {
+		value, ok := strings.CutPrefix(field, "--depth=")
+		if !ok {
+			return "", 0, fmt.Errorf("unknown deps option '%s'", field)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return "", 0, fmt.Errorf("depth must be a positive integer, got '%s'", value)
+		}
+		depth = n
+	}
+
+	return fields[0], depth, nil
+}
+
+// expandDeps expands the module-local packages imported by a Go file
+func expandDeps(spec string, turnNumber, startSection int, ctx MarkdownContext, opts Options) (string, []FileStat, error) {
+	fileName, depth, err := ParseDepsSpec(spec)
+	if err != nil {
+		return "", nil, err
+	}
+
+	imports, err := fileImports(fileName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	packages, err := loadPackages()
+	if err != nil {
+		return "", nil, err
+	}
+
+	files := dependencyFiles(imports, packages, depth)
+	if len(files) == 0 {
+		return "", nil, fmt.Errorf("'%s' imports no packages from this module", fileName)
+	}
+
+	var sections []string
+	var stats []FileStat
+	sectionNumber := startSection
+
+	for _, file := range files {
+		section, stat, err := expandFile(file, nil, turnNumber, sectionNumber, ctx, opts)
+		if err != nil {
+			return "", nil, err
+		}
+		if section == "" {
+			continue
+		}
+
+		sections = append(sections, section)
+		stats = append(stats, stat)
+		sectionNumber++
+	}
+
+	return strings.Join(sections, "\n\n"), stats, nil
+}
+
+// fileImports returns the import paths declared in a Go file
+func fileImports(fileName string) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), fileName, nil, parser.ImportsOnly)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("cannot find '%s'", fileName)
+		}
+		return nil, fmt.Errorf("failed to parse '%s': %w", fileName, err)
+	}
+
+	var imports []string
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err == nil {
+			imports = append(imports, path)
+		}
+	}
+	return imports, nil
+}
+
+// dependencyFiles walks imports breadth-first up to depth, returning the
+// sorted, relative Go files of every module-local package reached
+func dependencyFiles(imports []string, packages map[string]goPackage, depth int) []string {
+	cwd, _ := os.Getwd()
+	seen := make(map[string]bool)
+	var files []string
+
+	level := imports
+	for d := 0; d < depth && len(level) > 0; d++ {
+		var next []string
+		for _, importPath := range level {
+			pkg, ok := packages[importPath]
+			if !ok || seen[importPath] {
+				continue
+			}
+			seen[importPath] = true
+
+			for _, goFile := range pkg.GoFiles {
+				path := filepath.Join(pkg.Dir, goFile)
+				if rel, err := filepath.Rel(cwd, path); err == nil {
+					path = rel
+				}
+				files = append(files, path)
+			}
+			next = append(next, pkg.Imports...)
+		}
+		level = next
+	}
+
+	sort.Strings(files)
+	return files
+}
+
+// loadPackages lists the module's packages, reusing the cache until a
+// package directory changes
+func loadPackages() (map[string]goPackage, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	cachePath := filepath.Join(config.CachePath(), "deps", fmt.Sprintf("%x.json", sha256.Sum256([]byte(cwd))))
+	if cache, err := readDepsCache(cachePath); err == nil && depsCacheFresh(cache) {
+		return cache.Packages, nil
+	}
+
+	// Files edited while go list runs must count as changed since caching
+	listedAt := time.Now()
+	output, err := exec.Command("go", "list", "-json", "./...").Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("go list failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to run go list: %w", err)
+	}
+
+	// go list -json prints a stream of objects, not an array
+	packages := make(map[string]goPackage)
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for decoder.More() {
+		var pkg goPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			return nil, fmt.Errorf("failed to parse go list output: %w", err)
+		}
+		packages[pkg.ImportPath] = pkg
+	}
+
+	writeDepsCache(cachePath, &depsCache{CachedAt: listedAt, Packages: packages})
+	return packages, nil
+}
+
+func readDepsCache(path string) (*depsCache, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cache depsCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
+
+// depsCacheFresh reports whether no package changed since caching. Adding
+// or removing a file updates its directory's mtime; editing one in place,
+// e.g. to change its imports, only updates the file's.
+func depsCacheFresh(cache *depsCache) bool {
+	for _, pkg := range cache.Packages {
+		info, err := os.Stat(pkg.Dir)
+		if err != nil || info.ModTime().After(cache.CachedAt) {
+			return false
+		}
+		for _, file := range pkg.GoFiles {
+			info, err := os.Stat(filepath.Join(pkg.Dir, file))
+			if err != nil || info.ModTime().After(cache.CachedAt) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func writeDepsCache(path string, cache *depsCache) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,56 @@
+package expand
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/rana/ask/internal/config"
+)
+
+// decodeUTF16 converts content starting with a UTF-16 byte order mark to
+// UTF-8, reporting whether it did. A trailing odd byte is dropped.
+func decodeUTF16(content []byte) ([]byte, bool) {
+	if len(content) < 2 {
+		return content, false
+	}
+
+	var bigEndian bool
+	switch {
+	case content[0] == 0xFF && content[1] == 0xFE:
+		bigEndian = false
+	case content[0] == 0xFE && content[1] == 0xFF:
+		bigEndian = true
+	default:
+		return content, false
+	}
+
+	units := make([]uint16, 0, (len(content)-2)/2)
+	for i := 2; i+1 < len(content); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(content[i])<<8|uint16(content[i+1]))
+		} else {
+			units = append(units, uint16(content[i+1])<<8|uint16(content[i]))
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, r := range utf16.Decode(units) {
+		buf.Write(utf8.AppendRune(nil, r))
+	}
+	return buf.Bytes(), true
+}
+
+// decodeContent converts UTF-16 files to UTF-8 when expandCfg allows it;
+// other content is returned unchanged
+func decodeContent(fileName string, content []byte, expandCfg *config.Expand, opts Options) []byte {
+	if !expandCfg.AllowUTF16 {
+		return content
+	}
+	decoded, ok := decodeUTF16(content)
+	if ok && !opts.Quiet {
+		fmt.Printf("Converting UTF-16 file: %s\n", fileName)
+	}
+	return decoded
+}
@@ -6,7 +6,10 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/rana/ask/internal/config"
 	"github.com/rana/ask/internal/filter"
@@ -18,28 +21,120 @@ type FileStat struct {
 	Tokens int
 }
 
+// ReferencePattern matches [[file]], [[dir/]], [[dir/**/]] and [[dir/*.go]] references
+var ReferencePattern = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
+
+// Options controls a single expansion run
+type Options struct {
+	SessionModTime time.Time      // Files modified after this are stale; zero disables the check
+	FreshOnly      bool           // Fail instead of warning on stale files
+	FailFast       bool           // Fail instead of skipping unreadable or binary files
+	Quiet          bool           // Suppress skip and staleness warnings
+	Expand         *config.Expand // Rules to use instead of the configured ones
+	FirstSection   int            // Number of the first section; the next free one is FirstSection+len(stats)
+
+	// Skip leaves the references it returns true for unexpanded
+	Skip func(ref string) bool
+}
+
+// sectionHeading matches "## [3.4] file" but not turn headers like "# [3] Human"
+var sectionHeading = regexp.MustCompile(`(?m)^#+ \[(?:\d+\.)+(\d+)\] `)
+
+// LastSectionNumber returns the highest section number already expanded in content
+func LastSectionNumber(content string) int {
+	last := 0
+	for _, match := range sectionHeading.FindAllStringSubmatch(content, -1) {
+		if n, _ := strconv.Atoi(match[1]); n > last {
+			last = n
+		}
+	}
+	return last
+}
+
+// expandedHeading matches a section heading at the start of text, capturing the file name
+var expandedHeading = regexp.MustCompile(`^#+ \[(?:\d+\.)+\d+\] (\S+)`)
+
+// alreadyExpanded reports whether the text right after a reference is the
+// section it expanded to, as left behind by an earlier run
+func alreadyExpanded(after, path string) bool {
+	match := expandedHeading.FindStringSubmatch(strings.TrimLeft(after, " \t\r\n"))
+	if match == nil {
+		return false
+	}
+
+	fileName := match[1]
+	switch {
+	case strings.HasPrefix(path, DepsPrefix):
+		return true // Dependencies expand to files other than the one named
+	case strings.HasSuffix(path, "/"):
+		dir := strings.TrimSuffix(strings.TrimSuffix(path, "**/"), "/")
+		return strings.HasPrefix(fileName, dir+"/")
+	case IsGlob(path):
+		matched, _ := filepath.Match(path, fileName)
+		return matched
+	default:
+		file, _, _ := ParseLineRange(path)
+		return fileName == file
+	}
+}
+
 // ExpandReferences expands [[file]] and [[dir/]] references in content
-func ExpandReferences(content string, turnNumber int) (string, []FileStat, error) {
-	cfg, err := config.Load()
+func ExpandReferences(content string, turnNumber int, opts Options) (string, []FileStat, error) {
+	expandCfg := opts.Expand
+	if expandCfg == nil {
+		var err error
+		if expandCfg, err = config.LoadExpandConfig(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	// Footnotes go first, so their text may hold references of its own
+	content, err := expandFootnotes(content)
 	if err != nil {
-		cfg = config.Defaults()
+		return "", nil, err
+	}
+
+	if CountStdinReferences(content) > 1 {
+		return "", nil, fmt.Errorf("only one [[stdin]] reference is allowed per session")
 	}
 
-	pattern := regexp.MustCompile(`\[\[([^\]]+)\]\]`)
-	matches := pattern.FindAllStringSubmatch(content, -1)
-	matchIndices := pattern.FindAllStringSubmatchIndex(content, -1)
+	matches := ReferencePattern.FindAllStringSubmatch(content, -1)
+	matchIndices := ReferencePattern.FindAllStringSubmatchIndex(content, -1)
 
 	if len(matches) == 0 {
 		return content, nil, nil
 	}
 
+	// Replacements are spliced in by position once all are known, since the
+	// same reference may appear again after one that was left in place
+	type splice struct {
+		start, end int
+		text       string
+	}
+	var splices []splice
+
 	var stats []FileStat
-	expanded := content
 	sectionNumber := 1
+	if opts.FirstSection > 0 {
+		sectionNumber = opts.FirstSection
+	}
+	// Don't reuse numbers of sections expanded by an earlier run
+	if last := LastSectionNumber(content); last >= sectionNumber {
+		sectionNumber = last + 1
+	}
 
 	for i, match := range matches {
-		fullMatch := match[0] // [[file]] or [[dir/]] or [[dir/**/]]
-		path := match[1]      // file or dir/ or dir/**/
+		path := match[1] // file, dir/, dir/**/, dir/*.go or deps:file.go
+		replace := func(text string) {
+			splices = append(splices, splice{matchIndices[i][0], matchIndices[i][1], text})
+		}
+
+		if alreadyExpanded(content[matchIndices[i][1]:], path) {
+			continue
+		}
+		if opts.Skip != nil && opts.Skip(path) {
+			continue
+		}
 
 		// Detect markdown context at this reference position
 		// Use the original content and position for context detection
@@ -51,42 +146,87 @@ func ExpandReferences(content string, turnNumber int) (string, []FileStat, error
 			path = strings.TrimSuffix(path, "/**/") + "/" // Normalize to dir/
 		}
 
-		if strings.HasSuffix(path, "/") {
+		if path == StdinReference {
+			stdinExpanded, stdinStat, err := expandStdin(turnNumber, sectionNumber, ctx)
+			if err != nil {
+				return "", nil, err
+			}
+
+			replace(stdinExpanded)
+			stats = append(stats, stdinStat)
+			sectionNumber++
+		} else if strings.HasPrefix(path, DepsPrefix) {
+			depsExpanded, depsStats, err := expandDeps(path, turnNumber, sectionNumber, ctx, opts)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to expand '%s': %w", path, err)
+			}
+
+			replace(depsExpanded)
+			stats = append(stats, depsStats...)
+			sectionNumber += len(depsStats)
+		} else if strings.HasSuffix(path, "/") {
 			dirPath := strings.TrimSuffix(path, "/")
 
-			recursive := cfg.Expand.Recursive || forceRecursive
+			recursive := expandCfg.Recursive || forceRecursive
 
 			dirExpanded, dirStats, err := expandDirectoryWithOptions(
-				dirPath, turnNumber, sectionNumber, &cfg.Expand, recursive, 0, ctx,
+				dirPath, turnNumber, sectionNumber, expandCfg, recursive, 0, ctx, opts,
 			)
 			if err != nil {
 				return "", nil, fmt.Errorf("failed to expand directory '%s': %w", dirPath, err)
 			}
 
-			expanded = strings.Replace(expanded, fullMatch, dirExpanded, 1)
+			replace(dirExpanded)
 			stats = append(stats, dirStats...)
-			sectionNumber += len(dirStats) // Increment by number of files added
+			if expandCfg.DirectoryHeading {
+				sectionNumber++ // Files are numbered under the directory's own section
+			} else {
+				sectionNumber += len(dirStats) // Increment by number of files added
+			}
+		} else if IsGlob(path) {
+			globExpanded, globStats, err := expandGlob(path, turnNumber, sectionNumber, ctx, opts)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to expand '%s': %w", path, err)
+			}
+
+			replace(globExpanded)
+			stats = append(stats, globStats...)
+			sectionNumber += len(globStats)
 		} else {
-			fileExpanded, fileStat, err := expandFile(path, turnNumber, sectionNumber, ctx)
+			fileName, lines, err := ParseLineRange(path)
+			if err != nil {
+				return "", nil, err
+			}
+
+			fileExpanded, fileStat, err := expandFile(fileName, lines, turnNumber, sectionNumber, ctx, opts)
 			if err != nil {
 				return "", nil, err
 			}
 
 			if fileExpanded != "" {
-				expanded = strings.Replace(expanded, fullMatch, fileExpanded, 1)
+				replace(fileExpanded)
 				stats = append(stats, fileStat)
 				sectionNumber++
 			} else {
-				expanded = strings.Replace(expanded, fullMatch, "", 1)
+				replace("")
 			}
 		}
 	}
 
+	expanded := content
+	for i := len(splices) - 1; i >= 0; i-- {
+		sp := splices[i]
+		expanded = expanded[:sp.start] + sp.text + expanded[sp.end:]
+	}
 	return expanded, stats, nil
 }
 
-// Update expandFile function to apply filtering:
-func expandFile(fileName string, turnNumber, sectionNumber int, ctx MarkdownContext) (string, FileStat, error) {
+// expandFile expands a single file, or only the given lines when lines is non-nil
+func expandFile(fileName string, lines *LineRange, turnNumber, sectionNumber int, ctx MarkdownContext, opts Options) (string, FileStat, error) {
+	if err := checkFreshness(fileName, opts); err != nil {
+		return "", FileStat{}, err
+	}
+
 	fileContent, err := os.ReadFile(fileName)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -95,8 +235,27 @@ func expandFile(fileName string, turnNumber, sectionNumber int, ctx MarkdownCont
 		return "", FileStat{}, fmt.Errorf("failed to read '%s': %w", fileName, err)
 	}
 
-	if isBinary(fileContent) {
-		fmt.Printf("Skipping binary file '%s'\n", fileName)
+	expandCfg := opts.Expand
+	if expandCfg == nil {
+		if expandCfg, err = config.LoadExpandConfig(); err != nil {
+			return "", FileStat{}, err
+		}
+	}
+	fileContent = decodeContent(fileName, fileContent, expandCfg, opts)
+
+	if lines != nil {
+		if fileContent, err = lines.extract(fileName, fileContent); err != nil {
+			return "", FileStat{}, fmt.Errorf("failed to expand '%s': %w", fileName, err)
+		}
+	}
+
+	if isBinaryFile(fileName, fileContent, expandCfg) {
+		if opts.FailFast {
+			return "", FileStat{}, fmt.Errorf("'%s' is a binary file", fileName)
+		}
+		if !opts.Quiet {
+			fmt.Printf("Skipping binary file '%s'\n", fileName)
+		}
 		return "", FileStat{}, nil
 	}
 
@@ -104,11 +263,15 @@ func expandFile(fileName string, turnNumber, sectionNumber int, ctx MarkdownCont
 	if cfg == nil {
 		cfg = config.Defaults()
 	}
-	filteredContent := filter.FilterContent(string(fileContent), fileName, &cfg.Filter)
+	langHint := LanguageHint(fileName)
+	filteredContent := filter.FilterContent(string(fileContent), langHint, &cfg.Filter)
 
-	langHint := getLanguageHint(fileName)
+	sectionContent := filteredContent
+	if lines != nil {
+		sectionContent = fmt.Sprintf("// lines %s\n%s", lines, filteredContent)
+	}
 
-	section := formatSection(ctx, turnNumber, sectionNumber, fileName, langHint, filteredContent)
+	section := formatSection(ctx, turnNumber, sectionNumber, fileName, langHint, sectionContent)
 
 	tokens := len(filteredContent) / 4 // Rough approximation
 	stat := FileStat{File: fileName, Tokens: tokens}
@@ -124,11 +287,16 @@ func expandDirectoryWithOptions(
 	recursive bool,
 	depth int,
 	ctx MarkdownContext,
+	opts Options,
 ) (string, []FileStat, error) {
 	if depth >= expandCfg.MaxDepth {
 		return "", nil, nil
 	}
 
+	if depth == 0 && expandCfg.DirectoryHeading {
+		return expandUnderHeading(dirPath, turnNumber, startSection, expandCfg, recursive, ctx, opts)
+	}
+
 	info, err := os.Stat(dirPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -140,6 +308,12 @@ func expandDirectoryWithOptions(
 		return "", nil, fmt.Errorf("'%s' is not a directory", dirPath)
 	}
 
+	// Rules in dirPath/expand.toml apply to this directory and its children
+	expandCfg, err = config.LoadDirectoryExpand(dirPath, expandCfg)
+	if err != nil {
+		return "", nil, err
+	}
+
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to read directory '%s': %w", dirPath, err)
@@ -156,14 +330,14 @@ func expandDirectoryWithOptions(
 			if !isExcludedDirectory(name, expandCfg) {
 				subdirs = append(subdirs, fullPath)
 			}
-		} else {
+		} else if name != config.DirectoryExpandFile {
 			if shouldIncludeFile(name, fullPath, expandCfg) {
 				files = append(files, fullPath)
 			}
 		}
 	}
 
-	sort.Strings(files)
+	files = sortFiles(files, expandCfg.Sort)
 	sort.Strings(subdirs)
 
 	var sections []string
@@ -176,19 +350,31 @@ func expandDirectoryWithOptions(
 	}
 
 	for _, filePath := range files {
+		if err := checkFreshness(filePath, opts); err != nil {
+			return "", nil, err
+		}
+
 		fileContent, err := os.ReadFile(filePath)
 		if err != nil {
-			fmt.Printf("Skipping '%s': %v\n", filePath, err)
+			if opts.FailFast {
+				return "", nil, fmt.Errorf("failed to read '%s': %w", filePath, err)
+			}
+			if !opts.Quiet {
+				fmt.Printf("Skipping '%s': %v\n", filePath, err)
+			}
 			continue
 		}
+		fileContent = decodeContent(filePath, fileContent, expandCfg, opts)
 
-		if isBinary(fileContent) {
+		if isBinaryFile(filePath, fileContent, expandCfg) {
+			if opts.FailFast {
+				return "", nil, fmt.Errorf("'%s' is a binary file", filePath)
+			}
 			continue
 		}
 
-		filteredContent := filter.FilterContent(string(fileContent), filePath, &cfg.Filter)
-
-		langHint := getLanguageHint(filePath)
+		langHint := LanguageHint(filePath)
+		filteredContent := filter.FilterContent(string(fileContent), langHint, &cfg.Filter)
 
 		section := formatSection(ctx, turnNumber, sectionNumber, filePath, langHint, filteredContent)
 
@@ -203,10 +389,15 @@ func expandDirectoryWithOptions(
 	if recursive {
 		for _, subdir := range subdirs {
 			subExpanded, subStats, err := expandDirectoryWithOptions(
-				subdir, turnNumber, sectionNumber, expandCfg, recursive, depth+1, ctx,
+				subdir, turnNumber, sectionNumber, expandCfg, recursive, depth+1, ctx, opts,
 			)
 			if err != nil {
-				fmt.Printf("Warning: skipping '%s': %v\n", subdir, err)
+				if opts.FailFast {
+					return "", nil, err
+				}
+				if !opts.Quiet {
+					fmt.Printf("Warning: skipping '%s': %v\n", subdir, err)
+				}
 				continue
 			}
 
@@ -222,7 +413,68 @@ func expandDirectoryWithOptions(
 		return "", nil, fmt.Errorf("no matching files in directory '%s'", dirPath)
 	}
 
-	return strings.Join(sections, "\n\n"), stats, nil
+	separator := expandCfg.SectionSeparator
+	if separator == "" {
+		separator = "\n\n"
+	}
+	return strings.Join(sections, separator), stats, nil
+}
+
+// expandUnderHeading expands a directory beneath a "## [1.2] src/ (8 files)"
+// heading, with its files numbered and nested one level deeper
+func expandUnderHeading(
+	dirPath string,
+	turnNumber, sectionNumber int,
+	expandCfg *config.Expand,
+	recursive bool,
+	ctx MarkdownContext,
+	opts Options,
+) (string, []FileStat, error) {
+	number := fmt.Sprintf("%d.%d", turnNumber, sectionNumber)
+	if ctx.NumberPrefix != "" {
+		number = fmt.Sprintf("%s.%d", ctx.NumberPrefix, sectionNumber)
+	}
+
+	inner := MarkdownContext{HeaderLevel: ctx.HeaderLevel + 1, NumberPrefix: number}
+	if inner.HeaderLevel > 6 {
+		inner.HeaderLevel = 6 // Max markdown header level
+	}
+
+	flat := *expandCfg
+	flat.DirectoryHeading = false
+
+	body, stats, err := expandDirectoryWithOptions(dirPath, turnNumber, 1, &flat, recursive, 0, inner, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	heading := fmt.Sprintf("%s [%s] %s/ (%d files)", strings.Repeat("#", ctx.HeaderLevel), number, dirPath, len(stats))
+	return heading + "\n\n" + body, stats, nil
+}
+
+// checkFreshness warns (or fails with FreshOnly) when a file changed after the session
+func checkFreshness(filePath string, opts Options) error {
+	if opts.SessionModTime.IsZero() {
+		return nil
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil || !info.ModTime().After(opts.SessionModTime) {
+		// Missing files are reported by the read that follows
+		return nil
+	}
+
+	const layout = "Mon Jan 2 15:04"
+	msg := fmt.Sprintf("%s modified after session started (session: %s, file: %s)",
+		filePath, opts.SessionModTime.Format(layout), info.ModTime().Format(layout))
+
+	if opts.FreshOnly {
+		return fmt.Errorf("%s", msg)
+	}
+	if !opts.Quiet {
+		fmt.Printf("Warning: %s\n", msg)
+	}
+	return nil
 }
 
 // isExcludedDirectory checks if a directory should be excluded
@@ -283,12 +535,31 @@ func shouldIncludeFile(fileName string, filePath string, expandCfg *config.Expan
 	return false
 }
 
-// isBinary checks if content appears to be binary
+// isBinaryFile checks content with isBinary unless the file's extension is
+// allowed to look binary
+func isBinaryFile(fileName string, content []byte, expandCfg *config.Expand) bool {
+	ext := strings.TrimPrefix(filepath.Ext(fileName), ".")
+	for _, allowed := range expandCfg.AllowBinaryExtensions {
+		if ext != "" && strings.EqualFold(ext, strings.TrimPrefix(allowed, ".")) {
+			return false
+		}
+	}
+	return isBinary(content)
+}
+
+// isBinary checks if content appears to be binary. A few stray null bytes
+// in otherwise valid UTF-8 don't count.
 func isBinary(content []byte) bool {
+	nulls := 0
 	for _, b := range content {
 		if b == 0 {
-			return true
+			nulls++
 		}
 	}
-	return false
+	if nulls == 0 {
+		return false
+	}
+
+	// Text if fewer than 0.1% of bytes are null
+	return nulls*1000 >= len(content) || !utf8.Valid(content)
 }
@@ -0,0 +1,59 @@
+package expand
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// footnoteDefinition matches "[^1]: text" and any indented lines continuing it
+	footnoteDefinition = regexp.MustCompile(`(?m)^\[\^([\w-]+)\]:[ \t]*(.*(?:\n(?:    |\t).*)*)\n?`)
+	// footnoteReference matches [[^1]]
+	footnoteReference = regexp.MustCompile(`\[\[\^([\w-]+)\]\]`)
+)
+
+// IsFootnote reports whether a [[…]] reference names a footnote, e.g. ^1
+func IsFootnote(ref string) bool {
+	return strings.HasPrefix(ref, "^")
+}
+
+// expandFootnotes replaces [[^1]] with the text of the turn's "[^1]: …"
+// definition and removes the definitions it used. Footnotes only cited
+// the usual markdown way, as [^2], are left alone.
+func expandFootnotes(content string) (string, error) {
+	if !footnoteReference.MatchString(content) {
+		return content, nil
+	}
+
+	// First pass: collect definitions
+	definitions := make(map[string]string)
+	for _, match := range footnoteDefinition.FindAllStringSubmatch(content, -1) {
+		lines := strings.Split(match[2], "\n")
+		for i := range lines {
+			lines[i] = strings.TrimPrefix(strings.TrimPrefix(lines[i], "\t"), "    ")
+		}
+		definitions[match[1]] = strings.TrimSpace(strings.Join(lines, "\n"))
+	}
+
+	used := make(map[string]bool)
+	for _, match := range footnoteReference.FindAllStringSubmatch(content, -1) {
+		if _, ok := definitions[match[1]]; !ok {
+			return "", fmt.Errorf("footnote [^%s] is referenced but not defined", match[1])
+		}
+		used[match[1]] = true
+	}
+
+	// Second pass: substitute references, then drop the definitions
+	expanded := footnoteReference.ReplaceAllStringFunc(content, func(ref string) string {
+		return definitions[footnoteReference.FindStringSubmatch(ref)[1]]
+	})
+	expanded = footnoteDefinition.ReplaceAllStringFunc(expanded, func(definition string) string {
+		if used[footnoteDefinition.FindStringSubmatch(definition)[1]] {
+			return ""
+		}
+		return definition
+	})
+
+	return expanded, nil
+}
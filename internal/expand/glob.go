@@ -0,0 +1,70 @@
+package expand
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsGlob reports whether a reference is a pattern like src/*.go rather than a path
+func IsGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// GlobFiles returns the files matching pattern, sorted. Matching directories
+// are left out, since globs don't recurse; use [[dir/**/]] for that.
+func GlobFiles(pattern string) ([]string, error) {
+	for _, part := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if part == ".." {
+			return nil, fmt.Errorf("glob '%s' must not contain '..'", pattern)
+		}
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob '%s': %w", pattern, err)
+	}
+
+	var files []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && !info.IsDir() {
+			files = append(files, match)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files matching '%s'", pattern)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// expandGlob expands each file matching pattern as its own section
+func expandGlob(pattern string, turnNumber, startSection int, ctx MarkdownContext, opts Options) (string, []FileStat, error) {
+	files, err := GlobFiles(pattern)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sections []string
+	var stats []FileStat
+	sectionNumber := startSection
+
+	for _, file := range files {
+		section, stat, err := expandFile(file, nil, turnNumber, sectionNumber, ctx, opts)
+		if err != nil {
+			return "", nil, err
+		}
+		if section == "" {
+			continue
+		}
+
+		sections = append(sections, section)
+		stats = append(stats, stat)
+		sectionNumber++
+	}
+
+	return strings.Join(sections, "\n\n"), stats, nil
+}
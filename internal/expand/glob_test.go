@@ -0,0 +1,61 @@
+package expand
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGlobFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.go", "a.go", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A directory whose name matches the glob, with a file inside it
+	if err := os.MkdirAll(filepath.Join(dir, "pkg.go"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pkg.go", "c.go"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("matches files sorted, skipping directories", func(t *testing.T) {
+		files, err := GlobFiles(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go")}
+		if !slices.Equal(files, want) {
+			t.Errorf("GlobFiles = %v, want %v", files, want)
+		}
+	})
+
+	t.Run("empty result", func(t *testing.T) {
+		pattern := filepath.Join(dir, "*.rs")
+		_, err := GlobFiles(pattern)
+		if err == nil || !strings.Contains(err.Error(), "no files matching '"+pattern+"'") {
+			t.Errorf("GlobFiles(%q) error = %v, want no files matching", pattern, err)
+		}
+	})
+
+	t.Run("only directories match", func(t *testing.T) {
+		pattern := filepath.Join(dir, "pkg.*")
+		_, err := GlobFiles(pattern)
+		if err == nil || !strings.Contains(err.Error(), "no files matching") {
+			t.Errorf("GlobFiles(%q) error = %v, want no files matching", pattern, err)
+		}
+	})
+
+	t.Run("rejects parent components", func(t *testing.T) {
+		for _, pattern := range []string{"../*.go", "src/../*.go", dir + "/pkg.go/../*.go"} {
+			_, err := GlobFiles(pattern)
+			if err == nil || !strings.Contains(err.Error(), "must not contain '..'") {
+				t.Errorf("GlobFiles(%q) error = %v, want '..' rejected", pattern, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package expand
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ReferenceModTime returns when the files behind a reference last changed:
+// the file's mtime, or the newest mtime among the files of a directory or glob
+func ReferenceModTime(ref string) (time.Time, error) {
+	switch {
+	case strings.HasPrefix(ref, DepsPrefix):
+		return fileModTime(strings.TrimPrefix(ref, DepsPrefix))
+	case strings.HasSuffix(ref, "/"):
+		dir := strings.TrimSuffix(strings.TrimSuffix(ref, "**/"), "/")
+		var latest time.Time
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.ModTime().After(latest) {
+				latest = info.ModTime()
+			}
+			return nil
+		})
+		return latest, err
+	case IsGlob(ref):
+		files, err := GlobFiles(ref)
+		if err != nil {
+			return time.Time{}, err
+		}
+		var latest time.Time
+		for _, file := range files {
+			modTime, err := fileModTime(file)
+			if err != nil {
+				return time.Time{}, err
+			}
+			if modTime.After(latest) {
+				latest = modTime
+			}
+		}
+		return latest, nil
+	default:
+		file, _, err := ParseLineRange(ref)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return fileModTime(file)
+	}
+}
+
+func fileModTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
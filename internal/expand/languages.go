@@ -102,9 +102,9 @@ var filenameToLanguage = map[string]string{
 	"tsconfig.json":      "json",
 }
 
-// getLanguageHint returns a syntax highlighting hint for the given file path.
+// LanguageHint returns a syntax highlighting hint for the given file path.
 // It checks filename first, then extension, then falls back to the extension itself.
-func getLanguageHint(filePath string) string {
+func LanguageHint(filePath string) string {
 	base := filepath.Base(filePath)
 
 	// Priority 1: Check filename (Makefile, Dockerfile, etc.)
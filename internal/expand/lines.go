@@ -0,0 +1,66 @@
+package expand
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LineRange selects 1-indexed lines of a file; End 0 means through the last line
+type LineRange struct {
+	Start int
+	End   int
+}
+
+var linesParam = regexp.MustCompile(`^(.+):lines=(\d+)-(\d*)$`)
+
+// ParseLineRange splits "main.go:lines=42-89" into the file and its range.
+// References without a lines= parameter return a nil range.
+func ParseLineRange(ref string) (string, *LineRange, error) {
+	if !strings.Contains(ref, ":lines=") {
+		return ref, nil, nil
+	}
+
+	match := linesParam.FindStringSubmatch(ref)
+	if match == nil {
+		return "", nil, fmt.Errorf("invalid line range in '%s', expected file:lines=N-M or file:lines=N-", ref)
+	}
+
+	r := &LineRange{}
+	r.Start, _ = strconv.Atoi(match[2])
+	if match[3] != "" {
+		r.End, _ = strconv.Atoi(match[3])
+	}
+	if r.Start < 1 || (r.End != 0 && r.End < r.Start) {
+		return "", nil, fmt.Errorf("invalid line range %s in '%s'", r, ref)
+	}
+
+	return match[1], r, nil
+}
+
+// String formats the range as "42-89" or "42-"
+func (r LineRange) String() string {
+	if r.End == 0 {
+		return fmt.Sprintf("%d-", r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// extract returns the selected lines of content
+func (r LineRange) extract(fileName string, content []byte) ([]byte, error) {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	end := r.End
+	if end == 0 {
+		end = len(lines)
+	}
+	if r.Start > len(lines) || end > len(lines) {
+		return nil, fmt.Errorf("lines %s out of range: '%s' has %d lines", r, fileName, len(lines))
+	}
+
+	return []byte(strings.Join(lines[r.Start-1:end], "")), nil
+}
@@ -0,0 +1,142 @@
+package expand
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rana/ask/internal/config"
+)
+
+// FileSize is a file a reference would expand and its size on disk
+type FileSize struct {
+	File   string
+	Bytes  int64
+	Tokens int // Bytes/4, before filtering
+}
+
+// ReferenceSizes lists the files the references in content would expand,
+// using only os.Stat. Nothing is read or filtered, so binary files are
+// counted, line ranges count the whole file and deps references count only
+// the named file. Footnotes and [[stdin]] are left out.
+func ReferenceSizes(content string, opts Options) ([]FileSize, error) {
+	expandCfg := opts.Expand
+	if expandCfg == nil {
+		var err error
+		if expandCfg, err = config.LoadExpandConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	var sizes []FileSize
+	for _, match := range ReferencePattern.FindAllStringSubmatch(content, -1) {
+		path := match[1]
+		if IsFootnote(path) || path == StdinReference {
+			continue
+		}
+		if opts.Skip != nil && opts.Skip(path) {
+			continue
+		}
+
+		var files []string
+		var err error
+		switch {
+		case strings.HasPrefix(path, DepsPrefix):
+			var file string
+			if file, _, err = ParseDepsSpec(path); err == nil {
+				files = []string{file}
+			}
+		case strings.HasSuffix(path, "/"):
+			recursive := expandCfg.Recursive || strings.HasSuffix(path, "/**/")
+			dirPath := strings.TrimSuffix(strings.TrimSuffix(path, "**/"), "/")
+			files, err = directoryFiles(dirPath, expandCfg, recursive, 0)
+			if err == nil && len(files) == 0 {
+				err = fmt.Errorf("no matching files in directory '%s'", dirPath)
+			}
+		case IsGlob(path):
+			files, err = GlobFiles(path)
+		default:
+			var file string
+			if file, _, err = ParseLineRange(path); err == nil {
+				files = []string{file}
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to size '%s': %w", path, err)
+		}
+
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil, fmt.Errorf("cannot find '%s'", file)
+				}
+				return nil, fmt.Errorf("failed to stat '%s': %w", file, err)
+			}
+			sizes = append(sizes, FileSize{File: file, Bytes: info.Size(), Tokens: int(info.Size() / 4)})
+		}
+	}
+
+	return sizes, nil
+}
+
+// directoryFiles lists the files a directory reference expands, in the
+// same order and with the same rules as expandDirectoryWithOptions
+func directoryFiles(dirPath string, expandCfg *config.Expand, recursive bool, depth int) ([]string, error) {
+	if depth >= expandCfg.MaxDepth {
+		return nil, nil
+	}
+
+	info, err := os.Stat(dirPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("directory '%s' not found", dirPath)
+		}
+		return nil, fmt.Errorf("failed to stat '%s': %w", dirPath, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("'%s' is not a directory", dirPath)
+	}
+
+	expandCfg, err = config.LoadDirectoryExpand(dirPath, expandCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory '%s': %w", dirPath, err)
+	}
+
+	var files []string
+	var subdirs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		fullPath := filepath.Join(dirPath, name)
+
+		if entry.IsDir() {
+			if !isExcludedDirectory(name, expandCfg) {
+				subdirs = append(subdirs, fullPath)
+			}
+		} else if name != config.DirectoryExpandFile && shouldIncludeFile(name, fullPath, expandCfg) {
+			files = append(files, fullPath)
+		}
+	}
+
+	files = sortFiles(files, expandCfg.Sort)
+	if !recursive {
+		return files, nil
+	}
+
+	sort.Strings(subdirs)
+	for _, subdir := range subdirs {
+		subFiles, err := directoryFiles(subdir, expandCfg, recursive, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, subFiles...)
+	}
+	return files, nil
+}
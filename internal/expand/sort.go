@@ -0,0 +1,40 @@
+package expand
+
+import (
+	"os"
+	"sort"
+)
+
+// sortFiles orders files within a directory by mode: "name" (default),
+// "mtime" (newest first), "size" (largest first) or "type" (by language).
+// If any file can't be stat'ed, name order is used.
+func sortFiles(files []string, mode string) []string {
+	sorted := append([]string(nil), files...)
+	sort.Strings(sorted)
+
+	switch mode {
+	case "mtime", "size":
+		infos := make(map[string]os.FileInfo, len(sorted))
+		for _, file := range sorted {
+			info, err := os.Stat(file)
+			if err != nil {
+				return sorted
+			}
+			infos[file] = info
+		}
+
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a, b := infos[sorted[i]], infos[sorted[j]]
+			if mode == "mtime" {
+				return a.ModTime().After(b.ModTime())
+			}
+			return a.Size() > b.Size()
+		})
+	case "type":
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return LanguageHint(sorted[i]) < LanguageHint(sorted[j])
+		})
+	}
+
+	return sorted
+}
@@ -0,0 +1,40 @@
+package expand
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// StdinReference is the [[stdin]] reference, expanded to piped input
+const StdinReference = "stdin"
+
+// readStdin buffers all of stdin the first time it is called, so
+// [[stdin]] sees the same input however many turns are expanded
+var readStdin = sync.OnceValues(func() (string, error) {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprintln(os.Stderr, "Waiting for stdin input (Ctrl+D to finish):")
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+})
+
+// CountStdinReferences returns how often [[stdin]] appears in content
+func CountStdinReferences(content string) int {
+	return strings.Count(content, "[["+StdinReference+"]]")
+}
+
+// expandStdin renders piped input as a section
+func expandStdin(turnNumber, sectionNumber int, ctx MarkdownContext) (string, FileStat, error) {
+	input, err := readStdin()
+	if err != nil {
+		return "", FileStat{}, err
+	}
+	section := formatSection(ctx, turnNumber, sectionNumber, StdinReference, "", input)
+	return section, FileStat{File: StdinReference, Tokens: len(input) / 4}, nil
+}
@@ -0,0 +1,178 @@
+package filter
+
+import "strings"
+
+// commentSyntax lists the markers that start a comment line and the pairs
+// that delimit block comments in one language
+type commentSyntax struct {
+	line   []string
+	blocks [][2]string
+}
+
+var (
+	cStyle    = commentSyntax{line: []string{"//"}, blocks: [][2]string{{"/*", "*/"}}}
+	hashStyle = commentSyntax{line: []string{"#"}}
+	markup    = commentSyntax{blocks: [][2]string{{"<!--", "-->"}}}
+
+	// genericSyntax is used for languages without an entry in commentSyntaxes
+	genericSyntax = commentSyntax{
+		line:   []string{"//", "#", "--"},
+		blocks: [][2]string{{"/*", "*/"}, {"<!--", "-->"}, {`"""`, `"""`}, {"'''", "'''"}},
+	}
+)
+
+// commentSyntaxes is keyed by the language hints expand gives files
+var commentSyntaxes = map[string]commentSyntax{
+	// C-style: // lines and /* */ blocks
+	"go":         cStyle,
+	"rust":       cStyle,
+	"c":          cStyle,
+	"cpp":        cStyle,
+	"java":       cStyle,
+	"csharp":     cStyle,
+	"swift":      cStyle,
+	"kotlin":     cStyle,
+	"scala":      cStyle,
+	"javascript": cStyle,
+	"typescript": cStyle,
+	"protobuf":   cStyle,
+	"scss":       cStyle,
+	"less":       cStyle,
+	"stylus":     cStyle,
+
+	// # lines only
+	"python":     hashStyle,
+	"ruby":       hashStyle,
+	"bash":       hashStyle,
+	"zsh":        hashStyle,
+	"fish":       hashStyle,
+	"powershell": hashStyle,
+	"toml":       hashStyle,
+	"graphql":    hashStyle,
+	"makefile":   hashStyle,
+	"dockerfile": hashStyle,
+
+	// <!-- --> blocks only
+	"html":     markup,
+	"xml":      markup,
+	"markdown": markup,
+	"vue":      markup,
+	"svelte":   markup,
+
+	"css": {blocks: [][2]string{{"/*", "*/"}}},
+	"php": {line: []string{"//", "#"}, blocks: [][2]string{{"/*", "*/"}}},
+	"sql": {line: []string{"--"}, blocks: [][2]string{{"/*", "*/"}}},
+
+	// No comments
+	"json": {},
+	"text": {},
+}
+
+// StripComments removes comment lines from content, using the comment
+// syntax of lang, a language hint such as "go" or "yaml". Comments that
+// follow code on the same line are kept, except in YAML. Languages without
+// known syntax get the generic //, #, -- and block comment rules.
+func StripComments(content, lang string) string {
+	if lang == "yaml" {
+		return cleanBlankLines(stripYAMLComments(content))
+	}
+	syntax, ok := commentSyntaxes[lang]
+	if !ok {
+		syntax = genericSyntax
+	}
+	return cleanBlankLines(stripWith(content, syntax))
+}
+
+// stripWith drops comment lines and block comments that start a line
+func stripWith(content string, syntax commentSyntax) string {
+	var result []string
+	blockEnd := ""
+
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if blockEnd != "" {
+			if strings.Contains(line, blockEnd) {
+				blockEnd = ""
+			}
+			continue
+		}
+
+		if block, ok := startsBlock(trimmed, syntax.blocks); ok {
+			// A block that also ends on this line is a one-line comment
+			if !strings.Contains(trimmed[len(block[0]):], block[1]) {
+				blockEnd = block[1]
+			}
+			continue
+		}
+
+		// A shebang looks like a # comment but is needed to run the file
+		if i == 0 && strings.HasPrefix(trimmed, "#!") {
+			result = append(result, line)
+			continue
+		}
+		if startsLineComment(trimmed, syntax.line) {
+			continue
+		}
+
+		result = append(result, line)
+	}
+
+	return strings.Join(result, "\n")
+}
+
+func startsBlock(trimmed string, blocks [][2]string) ([2]string, bool) {
+	for _, block := range blocks {
+		if strings.HasPrefix(trimmed, block[0]) {
+			return block, true
+		}
+	}
+	return [2]string{}, false
+}
+
+func startsLineComment(trimmed string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.HasPrefix(trimmed, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripYAMLComments removes # comments, which in YAML start a line or follow
+// whitespace; a # inside a value such as a URL fragment or a quoted string
+// is kept
+func stripYAMLComments(content string) string {
+	var result []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		quote := byte(0)
+	scan:
+		for i := 0; i < len(line); i++ {
+			switch c := line[i]; {
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '#' && i > 0 && (line[i-1] == ' ' || line[i-1] == '\t'):
+				line = strings.TrimRight(line[:i], " \t")
+				break scan
+			}
+		}
+		result = append(result, line)
+	}
+	return strings.Join(result, "\n")
+}
+
+// cleanBlankLines collapses runs of blank lines left by removed comments
+func cleanBlankLines(content string) string {
+	for strings.Contains(content, "\n\n\n") {
+		content = strings.ReplaceAll(content, "\n\n\n", "\n\n")
+	}
+	return strings.TrimSpace(content)
+}
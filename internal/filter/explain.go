@@ -0,0 +1,76 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/rana/ask/internal/config"
+)
+
+// Stage names the filter step that removed a line
+type Stage string
+
+const (
+	Kept     Stage = ""
+	Header   Stage = "header"
+	Comments Stage = "comments"
+)
+
+// Line is one line of the original content and the stage that removed it, if any
+type Line struct {
+	Number    int // 1-indexed
+	Text      string
+	RemovedBy Stage
+}
+
+// Explain runs header and comment stripping as FilterContent would for a
+// file whose language hint is lang, and reports which original lines each
+// one removed. User rules are not traced.
+func Explain(content, lang string, cfg config.HeaderFilter, stripHeaders, stripComments bool) []Line {
+	var lines []Line
+	for i, text := range strings.Split(content, "\n") {
+		lines = append(lines, Line{Number: i + 1, Text: text})
+	}
+
+	if stripHeaders {
+		markRemoved(lines, stripHeader(content, cfg), Header)
+		content = keptText(lines)
+	}
+	if stripComments {
+		markRemoved(lines, StripComments(content, lang), Comments)
+	}
+
+	return lines
+}
+
+// markRemoved aligns the still kept lines with a stage's output, which only
+// deletes lines or shortens them, and marks the ones missing from it
+func markRemoved(lines []Line, output string, stage Stage) {
+	out := strings.Split(output, "\n")
+	j := 0
+	for i := range lines {
+		if lines[i].RemovedBy != Kept {
+			continue
+		}
+		// Stages trim surrounding whitespace, so compare trimmed lines. YAML
+		// comment stripping also cuts trailing comments, keeping a prefix.
+		text, kept := strings.TrimSpace(lines[i].Text), ""
+		if j < len(out) {
+			kept = strings.TrimSpace(out[j])
+		}
+		if j < len(out) && (text == kept || (kept != "" && strings.HasPrefix(text, kept))) {
+			j++
+			continue
+		}
+		lines[i].RemovedBy = stage
+	}
+}
+
+func keptText(lines []Line) string {
+	var kept []string
+	for _, line := range lines {
+		if line.RemovedBy == Kept {
+			kept = append(kept, line.Text)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -1,12 +1,15 @@
 package filter
 
 import (
+	"regexp"
 	"strings"
 
 	"github.com/rana/ask/internal/config"
 )
 
-func FilterContent(content string, filePath string, filterCfg *config.Filter) string {
+// FilterContent applies the configured filters to a file whose language
+// hint is lang, e.g. "go"
+func FilterContent(content string, lang string, filterCfg *config.Filter) string {
 	if !filterCfg.Enabled {
 		return content
 	}
@@ -16,12 +19,42 @@ func FilterContent(content string, filePath string, filterCfg *config.Filter) st
 	}
 
 	if filterCfg.StripAllComments {
-		content = stripAllComments(content)
+		content = StripComments(content, lang)
+	}
+
+	for _, rule := range filterCfg.Rules {
+		content = applyRule(content, rule)
 	}
 
 	return content
 }
 
+// applyRule applies a user rule. In line scope, lines emptied by the rule are
+// dropped so stripped statements don't leave blank lines behind.
+func applyRule(content string, rule config.FilterRule) string {
+	re, err := regexp.Compile(rule.Pattern)
+	if err != nil {
+		return content // Rules are validated when added
+	}
+
+	if rule.Scope == "block" {
+		return re.ReplaceAllString(content, rule.Replacement)
+	}
+
+	lines := strings.Split(content, "\n")
+	result := lines[:0]
+	for _, line := range lines {
+		if !re.MatchString(line) {
+			result = append(result, line)
+			continue
+		}
+		if replaced := re.ReplaceAllString(line, rule.Replacement); strings.TrimSpace(replaced) != "" {
+			result = append(result, replaced)
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
 func stripHeader(content string, cfg config.HeaderFilter) string {
 	// Check if content starts with a preserved pattern
 	trimmed := strings.TrimSpace(content)
@@ -61,61 +94,3 @@ func stripHeader(content string, cfg config.HeaderFilter) string {
 
 	return content
 }
-
-func stripAllComments(content string) string {
-	lines := strings.Split(content, "\n")
-	var result []string
-	inBlockComment := false
-	blockEnd := ""
-
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-
-		// Handle block comments
-		if !inBlockComment {
-			// Check for block comment starts
-			if strings.HasPrefix(trimmed, "/*") {
-				inBlockComment = true
-				blockEnd = "*/"
-				continue
-			} else if strings.HasPrefix(trimmed, "<!--") {
-				inBlockComment = true
-				blockEnd = "-->"
-				continue
-			} else if strings.HasPrefix(trimmed, `"""`) {
-				inBlockComment = true
-				blockEnd = `"""`
-				continue
-			} else if strings.HasPrefix(trimmed, "'''") {
-				inBlockComment = true
-				blockEnd = "'''"
-				continue
-			}
-		}
-
-		if inBlockComment {
-			if strings.Contains(line, blockEnd) {
-				inBlockComment = false
-			}
-			continue
-		}
-
-		// Skip single-line comments
-		if strings.HasPrefix(trimmed, "//") ||
-			strings.HasPrefix(trimmed, "#") ||
-			strings.HasPrefix(trimmed, "--") {
-			continue
-		}
-
-		// Keep non-comment lines
-		result = append(result, line)
-	}
-
-	// Clean up excessive blank lines
-	cleaned := strings.Join(result, "\n")
-	for strings.Contains(cleaned, "\n\n\n") {
-		cleaned = strings.ReplaceAll(cleaned, "\n\n\n", "\n\n")
-	}
-
-	return strings.TrimSpace(cleaned)
-}
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// run runs git in dir and returns its trimmed output
+func run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// RepoRoot returns the top directory of the work tree containing dir
+func RepoRoot(dir string) (string, error) {
+	return run(dir, "rev-parse", "--show-toplevel")
+}
+
+// CurrentBranch returns the name of the checked out branch, failing when
+// HEAD is detached
+func CurrentBranch(dir string) (string, error) {
+	branch, err := run(dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("HEAD is not on a branch")
+	}
+	return branch, nil
+}
+
+// GitPath returns the path of name inside the repository's .git directory
+func GitPath(dir, name string) (string, error) {
+	path, err := run(dir, "rev-parse", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return path, nil
+}
+
+// HooksDir returns the directory git runs hooks from: core.hooksPath when
+// set, otherwise the repository's hooks directory
+func HooksDir(dir string) (string, error) {
+	root, err := RepoRoot(dir)
+	if err != nil {
+		return "", err
+	}
+	if path, err := run(dir, "config", "core.hooksPath"); err == nil && path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		return path, nil
+	}
+
+	return GitPath(dir, "hooks")
+}
@@ -0,0 +1,48 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InstallHook adds line to the named hook, creating it if needed, and
+// points core.hooksPath at the hooks directory so git runs it. Existing hook
+// content is kept. It returns the hook's path.
+func InstallHook(dir, name, line string) (string, error) {
+	hooks, err := HooksDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(hooks, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", hooks, err)
+	}
+
+	path := filepath.Join(hooks, name)
+	content, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		content = []byte("#!/bin/sh\n")
+	case err != nil:
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if !strings.Contains(string(content), line) {
+		if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+			content = append(content, '\n')
+		}
+		content = append(content, line+"\n"...)
+		if err := os.WriteFile(path, content, 0755); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	if err := os.Chmod(path, 0755); err != nil {
+		return "", fmt.Errorf("failed to make %s executable: %w", path, err)
+	}
+
+	if _, err := run(dir, "config", "core.hooksPath", hooks); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/rana/ask/internal/session"
+)
+
+// indexFile is the index within the memory directory
+const indexFile = "index.toml"
+
+// maxChunk is the size at which a turn is split into several passages
+const maxChunk = 1200
+
+// Chunk is a passage of one session turn
+type Chunk struct {
+	Session string `toml:"session"` // Absolute path
+	Turn    int    `toml:"turn"`
+	Role    string `toml:"role"`
+	Text    string `toml:"text"`
+}
+
+// Index holds the passages searched for relevant context
+type Index struct {
+	BuiltAt time.Time `toml:"built_at"`
+	Chunks  []Chunk   `toml:"chunks"`
+}
+
+// Load reads the index in dir, returning an empty index if there is none
+func Load(dir string) (*Index, error) {
+	index := &Index{}
+	path := filepath.Join(dir, indexFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return index, nil
+	}
+	if _, err := toml.DecodeFile(path, index); err != nil {
+		return index, fmt.Errorf("failed to decode memory index: %w", err)
+	}
+	return index, nil
+}
+
+// Save writes the index to dir
+func (idx *Index) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	idx.BuiltAt = time.Now()
+	file, err := os.Create(filepath.Join(dir, indexFile))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return toml.NewEncoder(file).Encode(idx)
+}
+
+// Clear removes the memory directory and everything in it
+func Clear(dir string) error {
+	return os.RemoveAll(dir)
+}
+
+// Add splits each turn of the session at path into passages
+func (idx *Index) Add(path string, turns []session.Turn) {
+	for _, turn := range turns {
+		for _, text := range chunkText(turn.Content) {
+			idx.Chunks = append(idx.Chunks, Chunk{
+				Session: path,
+				Turn:    turn.Number,
+				Role:    turn.Role,
+				Text:    text,
+			})
+		}
+	}
+}
+
+// chunkText splits text at blank lines into passages of about maxChunk
+// bytes; a single longer paragraph is kept whole
+func chunkText(text string) []string {
+	var chunks []string
+	var current strings.Builder
+	for _, para := range strings.Split(text, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		if current.Len() > 0 && current.Len()+len(para) > maxChunk {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(para)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// stopWords are too common to say anything about relevance
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "can": true, "do": true, "for": true, "from": true, "has": true,
+	"have": true, "how": true, "if": true, "in": true, "is": true, "it": true, "its": true,
+	"me": true, "my": true, "not": true, "of": true, "on": true, "or": true, "so": true,
+	"that": true, "the": true, "this": true, "to": true, "was": true, "we": true, "what": true,
+	"when": true, "which": true, "will": true, "with": true, "you": true, "your": true,
+}
+
+// Result is a passage and how closely it matches the query
+type Result struct {
+	Chunk
+	Score float64 // Cosine similarity of TF-IDF vectors, 0 to 1
+}
+
+// Search returns up to k passages most similar to query, ignoring those
+// skip rejects. Passages sharing no terms with the query are never returned.
+func (idx *Index) Search(query string, k int, skip func(Chunk) bool) []Result {
+	queryTerms := termCounts(query)
+	if len(queryTerms) == 0 || len(idx.Chunks) == 0 {
+		return nil
+	}
+
+	docs := make([]map[string]int, len(idx.Chunks))
+	df := make(map[string]int)
+	for i, chunk := range idx.Chunks {
+		docs[i] = termCounts(chunk.Text)
+		for term := range docs[i] {
+			df[term]++
+		}
+	}
+
+	n := float64(len(docs))
+	idf := func(term string) float64 {
+		return math.Log(1 + n/float64(df[term]))
+	}
+
+	var queryNorm float64
+	for term, count := range queryTerms {
+		if df[term] > 0 {
+			w := float64(count) * idf(term)
+			queryNorm += w * w
+		}
+	}
+	if queryNorm == 0 {
+		return nil
+	}
+	queryNorm = math.Sqrt(queryNorm)
+
+	var results []Result
+	for i, doc := range docs {
+		if skip != nil && skip(idx.Chunks[i]) {
+			continue
+		}
+
+		var dot, docNorm float64
+		for term, count := range doc {
+			w := float64(count) * idf(term)
+			docNorm += w * w
+			if q, ok := queryTerms[term]; ok {
+				dot += w * float64(q) * idf(term)
+			}
+		}
+		if dot == 0 {
+			continue
+		}
+		results = append(results, Result{Chunk: idx.Chunks[i], Score: dot / (queryNorm * math.Sqrt(docNorm))})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// termCounts lowercases text and counts its words, ignoring stop words and single characters
+func termCounts(text string) map[string]int {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if len(word) > 1 && !stopWords[word] {
+			counts[word]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,165 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rana/ask/internal/expand"
+	"github.com/rana/ask/internal/session"
+)
+
+// obsidianUnsafe are characters Obsidian doesn't allow in note names
+var obsidianUnsafe = strings.NewReplacer(
+	"[", "", "]", "", "#", "", "^", "", "|", "-",
+	"/", "-", "\\", "-", ":", "-", "*", "", "?", "", "\"", "", "<", "", ">", "",
+)
+
+// ObsidianName makes name safe to use as an Obsidian note or folder name
+func ObsidianName(name string) string {
+	name = strings.TrimSpace(obsidianUnsafe.Replace(name))
+	name = strings.TrimLeft(name, ".") // Dot folders are hidden from the vault
+	if name == "" {
+		return "session"
+	}
+	return name
+}
+
+// ExportObsidian writes turns as linked notes in <vault>/ask/<name>/, one
+// per turn plus an index note named after the session. content is the raw
+// session, read for its title and tags; modified dates turns recorded
+// without timing. It returns the folder and the number of notes written.
+func ExportObsidian(vault, name, content string, turns []session.Turn, modified time.Time) (string, int, error) {
+	name = ObsidianName(name)
+	dir := filepath.Join(vault, "ask", name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	// Note names are unique across the vault so WikiLinks resolve by name alone
+	notes := make([]string, len(turns))
+	for i, turn := range turns {
+		notes[i] = fmt.Sprintf("%s - %02d %s", name, turn.Number, turn.Role)
+	}
+
+	meta := session.ReadMetadata(content)
+	sessionTags := append([]string{"ask"}, session.ReadTags(content, 0)...)
+
+	for i, turn := range turns {
+		date := modified
+		if !turn.Timestamp.IsZero() {
+			date = turn.Timestamp
+		}
+
+		var b strings.Builder
+		b.WriteString("---\n")
+		fmt.Fprintf(&b, "session: %s\n", strconv.Quote(name))
+		fmt.Fprintf(&b, "turn: %d\n", turn.Number)
+		fmt.Fprintf(&b, "role: %s\n", turn.Role)
+		fmt.Fprintf(&b, "date: %s\n", date.Format(time.RFC3339))
+		if turn.DurationSec > 0 {
+			fmt.Fprintf(&b, "duration: %d\n", turn.DurationSec)
+		}
+		writeYAMLList(&b, "tags", append(append([]string(nil), sessionTags...), session.ReadTags(content, turn.Number)...))
+		b.WriteString("---\n\n")
+
+		b.WriteString(obsidianLinks(turn.Content))
+		b.WriteString("\n\n---\n\n")
+		var nav []string
+		if i > 0 {
+			nav = append(nav, fmt.Sprintf("← [[%s]]", notes[i-1]))
+		}
+		nav = append(nav, fmt.Sprintf("[[%s|Index]]", name))
+		if i+1 < len(notes) {
+			nav = append(nav, fmt.Sprintf("[[%s]] →", notes[i+1]))
+		}
+		b.WriteString(strings.Join(nav, " | ") + "\n")
+
+		path := filepath.Join(dir, notes[i]+".md")
+		if err := session.WriteAtomic(path, []byte(b.String())); err != nil {
+			return "", 0, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	// The index note links every turn
+	var b strings.Builder
+	b.WriteString("---\n")
+	if meta.Title != "" {
+		fmt.Fprintf(&b, "title: %s\n", strconv.Quote(meta.Title))
+	}
+	fmt.Fprintf(&b, "date: %s\n", modified.Format(time.RFC3339))
+	fmt.Fprintf(&b, "turns: %d\n", len(turns))
+	writeYAMLList(&b, "tags", sessionTags)
+	b.WriteString("---\n\n")
+	heading := name
+	if meta.Title != "" {
+		heading = meta.Title
+	}
+	fmt.Fprintf(&b, "# %s\n\n", heading)
+	for i, turn := range turns {
+		fmt.Fprintf(&b, "- [[%s|%d %s]] %s\n", notes[i], turn.Number, turn.Role, obsidianLinks(firstLine(turn.Content)))
+	}
+
+	path := filepath.Join(dir, name+".md")
+	if err := session.WriteAtomic(path, []byte(b.String())); err != nil {
+		return "", 0, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return dir, len(turns) + 1, nil
+}
+
+// obsidianLinks rewrites [[file]] references outside code blocks as
+// WikiLinks to the file's note name, keeping the path as the alias
+func obsidianLinks(content string) string {
+	lines := strings.Split(content, "\n")
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		lines[i] = expand.ReferencePattern.ReplaceAllStringFunc(line, func(match string) string {
+			ref := match[2 : len(match)-2]
+			if strings.HasPrefix(ref, "^") || ref == expand.StdinReference {
+				return match // Footnotes and stdin aren't files
+			}
+			target, _, _ := strings.Cut(ref, ":lines=")
+			target = filepath.Base(strings.TrimSuffix(target, "/"))
+			target = strings.TrimSuffix(target, ".md")
+			if target == ref {
+				return match
+			}
+			return fmt.Sprintf("[[%s|%s]]", target, ref)
+		})
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeYAMLList writes key with items as a YAML block list
+func writeYAMLList(b *strings.Builder, key string, items []string) {
+	fmt.Fprintf(b, "%s:\n", key)
+	for _, item := range items {
+		fmt.Fprintf(b, "  - %s\n", strconv.Quote(item))
+	}
+}
+
+// firstLine returns the first non-empty line of content, shortened for a list entry
+func firstLine(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "<!--") {
+			continue
+		}
+		if runes := []rune(line); len(runes) > 80 {
+			line = string(runes[:77]) + "..."
+		}
+		return line
+	}
+	return ""
+}
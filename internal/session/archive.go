@@ -0,0 +1,235 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ArchiveIndex lists archived sessions
+type ArchiveIndex struct {
+	Entries []ArchiveEntry `toml:"entries"`
+}
+
+// ArchiveEntry describes one archived session
+type ArchiveEntry struct {
+	Name       string    `toml:"name"`     // e.g. "20250115-exploring-distributed-systems.md"
+	Path       string    `toml:"path"`     // Relative to the archive root
+	Original   string    `toml:"original"` // Absolute path before archiving
+	ArchivedAt time.Time `toml:"archived_at"`
+	Title      string    `toml:"title,omitempty"` // From the session's title annotation
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug derives a filename-safe slug from the first 40 characters of text
+func Slug(text string) string {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if runes := []rune(text); len(runes) > 40 {
+		text = string(runes[:40])
+	}
+
+	slug := strings.Trim(slugPattern.ReplaceAllString(text, "-"), "-")
+	if slug == "" {
+		return "session"
+	}
+	return slug
+}
+
+// IsSession reports whether the file looks like an Ask session
+func IsSession(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	_, err = ParseAnyFormat(string(content))
+	return err == nil
+}
+
+// Archive moves a session into root/YYYY/MM/YYYYMMDD-<slug><ext> and records it in the index
+func Archive(path, root string) (ArchiveEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	turns, err := ParseAnyFormat(string(content))
+	if err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	// Prefer the session's title, falling back to its first question
+	title := ReadMetadata(string(content)).Title
+	slug := "session"
+	if title != "" {
+		slug = Slug(title)
+	} else {
+		for _, turn := range turns {
+			if turn.Role == "Human" {
+				slug = Slug(turn.Content)
+				break
+			}
+		}
+	}
+
+	// Date the archive by when the session was last worked on
+	modTime := info.ModTime()
+	dir := filepath.Join(modTime.Format("2006"), modTime.Format("01"))
+	if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	// Keep the session's extension, e.g. .adoc, and avoid clobbering an
+	// earlier archive with the same slug
+	ext := filepath.Ext(path)
+	if ext == "" {
+		ext = ".md"
+	}
+	base := modTime.Format("20060102") + "-" + slug
+	name := base + ext
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(root, dir, name)); os.IsNotExist(err) {
+			break
+		}
+		name = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+
+	original, err := filepath.Abs(path)
+	if err != nil {
+		original = path
+	}
+
+	entry := ArchiveEntry{
+		Name:       name,
+		Path:       filepath.Join(dir, name),
+		Original:   original,
+		ArchivedAt: time.Now(),
+		Title:      title,
+	}
+
+	if err := moveFile(path, filepath.Join(root, entry.Path)); err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to move %s: %w", path, err)
+	}
+
+	// A broken index must not be overwritten, or every earlier entry is lost
+	index, err := LoadArchiveIndex(root)
+	if err != nil {
+		return entry, fmt.Errorf("archived to %s but failed to update index: %w", entry.Path, err)
+	}
+	index.Entries = append(index.Entries, entry)
+	if err := saveArchiveIndex(root, index); err != nil {
+		return entry, fmt.Errorf("archived to %s but failed to update index: %w", entry.Path, err)
+	}
+
+	return entry, nil
+}
+
+// Restore copies an archived session into dir, returning the restored path
+func Restore(name, root, dir string) (string, error) {
+	index, err := LoadArchiveIndex(root)
+	if err != nil {
+		return "", err
+	}
+
+	entry, ok := index.Find(name)
+	if !ok {
+		return "", fmt.Errorf("no archived session named '%s'. Run 'ask archive --list'", name)
+	}
+
+	target := filepath.Join(dir, filepath.Base(entry.Original))
+	if _, err := os.Stat(target); err == nil {
+		return "", fmt.Errorf("%s already exists", target)
+	}
+
+	content, err := os.ReadFile(filepath.Join(root, entry.Path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	if err := WriteAtomic(target, content); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", target, err)
+	}
+
+	return target, nil
+}
+
+// Find looks up an entry by name, with or without its extension
+func (idx *ArchiveIndex) Find(name string) (ArchiveEntry, bool) {
+	name = filepath.Base(name)
+	for i := len(idx.Entries) - 1; i >= 0; i-- {
+		entry := idx.Entries[i]
+		if entry.Name == name || strings.TrimSuffix(entry.Name, filepath.Ext(entry.Name)) == name {
+			return entry, true
+		}
+	}
+	return ArchiveEntry{}, false
+}
+
+// LoadArchiveIndex reads root/index.toml, returning an empty index if missing
+func LoadArchiveIndex(root string) (*ArchiveIndex, error) {
+	index := &ArchiveIndex{}
+
+	path := filepath.Join(root, "index.toml")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return index, nil
+	}
+
+	if _, err := toml.DecodeFile(path, index); err != nil {
+		return index, fmt.Errorf("failed to decode archive index: %w", err)
+	}
+
+	sort.SliceStable(index.Entries, func(i, j int) bool {
+		return index.Entries[i].ArchivedAt.Before(index.Entries[j].ArchivedAt)
+	})
+
+	return index, nil
+}
+
+// saveArchiveIndex writes index.toml atomically
+func saveArchiveIndex(root string, index *ArchiveIndex) error {
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(index); err != nil {
+		return err
+	}
+	return WriteAtomic(filepath.Join(root, "index.toml"), []byte(b.String()))
+}
+
+// moveFile renames src to dst, copying when they are on different filesystems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(src)
+}
@@ -0,0 +1,80 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CodeBlock represents a fenced code block within turn content
+type CodeBlock struct {
+	Lang    string // Info string after the opening fence, e.g. "bash"
+	Content string
+}
+
+// ExtractCodeBlocks finds all fenced code blocks in content
+func ExtractCodeBlocks(content string) []CodeBlock {
+	var blocks []CodeBlock
+
+	lines := strings.Split(content, "\n")
+	inBlock := false
+	fence := ""
+	var current CodeBlock
+	var body []string
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if !inBlock {
+			if n := countBackticks(trimmed); n >= 3 {
+				inBlock = true
+				fence = strings.Repeat("`", n)
+				current = CodeBlock{Lang: strings.ToLower(strings.TrimSpace(trimmed[n:]))}
+				body = nil
+			}
+			continue
+		}
+
+		// A closing fence has at least as many backticks and no info string
+		if n := countBackticks(trimmed); n >= len(fence) && strings.TrimSpace(trimmed[n:]) == "" {
+			current.Content = strings.Join(body, "\n")
+			blocks = append(blocks, current)
+			inBlock = false
+			continue
+		}
+
+		body = append(body, line)
+	}
+
+	return blocks
+}
+
+// IsShell reports whether the block is a bash or sh block
+func (b CodeBlock) IsShell() bool {
+	return b.Lang == "bash" || b.Lang == "sh"
+}
+
+// countBackticks returns the number of leading backticks in s
+func countBackticks(s string) int {
+	n := 0
+	for n < len(s) && s[n] == '`' {
+		n++
+	}
+	return n
+}
+
+var filePathComment = regexp.MustCompile(`(?m)^//\s*file:\s*(.+)$`)
+
+// FilePath returns the path named by a "// file: path" comment in the
+// first three lines of the block, or "" if there is none
+func (b CodeBlock) FilePath() string {
+	lines := strings.SplitN(b.Content, "\n", 4)
+	if len(lines) > 3 {
+		lines = lines[:3]
+	}
+
+	match := filePathComment.FindStringSubmatch(strings.Join(lines, "\n"))
+	if match == nil {
+		return ""
+	}
+	return strings.TrimSpace(match[1])
+}
@@ -0,0 +1,48 @@
+package session
+
+// EstimateTokens approximates the tokens in turns the same way expansion does
+func EstimateTokens(turns []Turn) int {
+	total := 0
+	for _, turn := range turns {
+		total += len(turn.Content) / 4 // Rough approximation
+	}
+	return total
+}
+
+// TrimToTokenBudget drops the oldest Human/AI pairs until the estimated
+// total fits budget. Pairs with a pinned turn and the last Human turn are
+// always kept, so the result may still exceed budget. It returns the kept
+// turns and how many turns were dropped.
+func TrimToTokenBudget(turns []Turn, budget int) ([]Turn, int) {
+	kept := append([]Turn(nil), turns...)
+	total := EstimateTokens(kept)
+
+	lastHuman := -1
+	for i := len(kept) - 1; i >= 0; i-- {
+		if kept[i].Role == "Human" {
+			lastHuman = i
+			break
+		}
+	}
+
+	i := 0
+	for total > budget && i < lastHuman {
+		// A pair is a Human turn and the AI response that follows it
+		end := i + 1
+		if end < lastHuman && kept[end].Role == "AI" {
+			end++
+		}
+
+		// A pin on either turn keeps the pair
+		if kept[i].Pinned || kept[end-1].Pinned {
+			i = end
+			continue
+		}
+
+		total -= EstimateTokens(kept[i:end])
+		kept = append(kept[:i], kept[end:]...)
+		lastHuman -= end - i
+	}
+
+	return kept, len(turns) - len(kept)
+}
@@ -0,0 +1,102 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ExpansionMeta records what reference expansion added to a Human turn
+type ExpansionMeta struct {
+	Files  int
+	Tokens int // Estimated
+	At     time.Time
+}
+
+// expansionComment matches the metadata line chat appends to an expanded turn
+var expansionComment = regexp.MustCompile(`(?m)^<!-- expanded: (\d+) files?, (\d+) tokens, (\S+) -->[ \t]*\n?`)
+
+// Comment renders the metadata as the HTML comment stored in the session
+func (m ExpansionMeta) Comment() string {
+	return fmt.Sprintf("<!-- expanded: %d files, %d tokens, %s -->",
+		m.Files, m.Tokens, m.At.UTC().Format(time.RFC3339))
+}
+
+// ParseExpansionMetadata returns the expansion metadata of each Human turn,
+// keyed by turn number. Turns without a comment are absent.
+func ParseExpansionMetadata(content string) map[int]ExpansionMeta {
+	metas := make(map[int]ExpansionMeta)
+
+	turns, err := ParseAnyFormat(content)
+	if err != nil {
+		return metas
+	}
+
+	for _, turn := range turns {
+		if turn.Role != "Human" {
+			continue
+		}
+		for _, match := range expansionComment.FindAllStringSubmatch(turn.Raw, -1) {
+			files, _ := strconv.Atoi(match[1])
+			tokens, _ := strconv.Atoi(match[2])
+			at, _ := time.Parse(time.RFC3339, match[3])
+			metas[turn.Number] = ExpansionMeta{Files: files, Tokens: tokens, At: at}
+		}
+	}
+
+	return metas
+}
+
+// stripExpansionComments removes expansion metadata so it isn't sent to the model
+func stripExpansionComments(content string) string {
+	return expansionComment.ReplaceAllString(content, "")
+}
+
+// expansionRecord matches a front matter line recording the file mtime a
+// reference had when it was expanded in a turn
+var expansionRecord = regexp.MustCompile(`<!--\s*ask-expanded:\s*(\d+)\s+(\S+)\s+(.*?)\s*-->[ \t]*\n?`)
+
+// GetExpansionRecord returns the references of turn recorded in the session
+// at path, with the file mtime each had when it was expanded
+func GetExpansionRecord(path string, turn int) map[string]time.Time {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return make(map[string]time.Time)
+	}
+	return ParseExpansionRecord(string(content), turn)
+}
+
+// ParseExpansionRecord is GetExpansionRecord for session content
+func ParseExpansionRecord(content string, turn int) map[string]time.Time {
+	record := make(map[string]time.Time)
+	for _, match := range expansionRecord.FindAllStringSubmatch(frontMatter(content), -1) {
+		if n, _ := strconv.Atoi(match[1]); n != turn {
+			continue
+		}
+		if modTime, err := time.Parse(time.RFC3339Nano, match[2]); err == nil {
+			record[match[3]] = modTime
+		}
+	}
+	return record
+}
+
+// RecordExpansion writes or updates the front matter line recording that ref
+// was expanded in turn while its files had modTime
+func RecordExpansion(content string, turn int, ref string, modTime time.Time) string {
+	line := fmt.Sprintf("<!-- ask-expanded: %d %s %s -->", turn, modTime.UTC().Format(time.RFC3339Nano), ref)
+
+	front := frontMatter(content)
+	for _, loc := range expansionRecord.FindAllStringSubmatchIndex(front, -1) {
+		if n, _ := strconv.Atoi(front[loc[2]:loc[3]]); n == turn && front[loc[6]:loc[7]] == ref {
+			return content[:loc[0]] + line + "\n" + content[loc[1]:]
+		}
+	}
+
+	// Keep records together at the top, apart from other annotations
+	if expansionRecord.MatchString(front) {
+		return line + "\n" + content
+	}
+	return line + "\n\n" + content
+}
@@ -0,0 +1,37 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultFenceDepth is how many backticks wrap an AI response by default
+const DefaultFenceDepth = 4
+
+// markdownFence matches the opening ````markdown of a wrapped AI response
+var markdownFence = regexp.MustCompile("^(`{3,})markdown\n?")
+
+// fenceFor returns a fence of at least depth backticks that is longer
+// than any run of backticks in content, so content can't close it early
+func fenceFor(content string, depth int) string {
+	if n := longestBacktickRun(content) + 1; n > depth {
+		depth = n
+	}
+	return strings.Repeat("`", depth)
+}
+
+// longestBacktickRun returns the length of the longest run of backticks in s
+func longestBacktickRun(s string) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != '`' {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
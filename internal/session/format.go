@@ -0,0 +1,154 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatAITurn normalizes the given AI turn in place and returns the updated session
+func FormatAITurn(content string, turnNumber int) (string, error) {
+	header := fmt.Sprintf("# [%d] AI", turnNumber)
+
+	headerPos := strings.LastIndex(content, header)
+	if headerPos == -1 {
+		return "", fmt.Errorf("turn %d is not an AI turn", turnNumber)
+	}
+
+	// The section runs from after the header line to the next header or EOF,
+	// keeping any annotation such as a timestamp on the header line
+	bodyStart := headerPos + len(header)
+	if lineEnd := strings.Index(content[bodyStart:], "\n"); lineEnd != -1 {
+		bodyStart += lineEnd
+	}
+	bodyEnd := len(content)
+	if nextHeaderPos := strings.Index(content[bodyStart:], "\n# ["); nextHeaderPos != -1 {
+		bodyEnd = bodyStart + nextHeaderPos
+	}
+
+	body := NormalizeResponse(content[bodyStart:bodyEnd])
+
+	return content[:bodyStart] + body + content[bodyEnd:], nil
+}
+
+// NormalizeResponse cleans up whitespace, line endings and code fences
+func NormalizeResponse(text string) string {
+	// Normalize line endings to LF
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	lines := strings.Split(text, "\n")
+	result := make([]string, 0, len(lines))
+	blankRun := 0
+
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+
+		// Collapse runs of 3+ blank lines to 2
+		if line == "" {
+			blankRun++
+			if blankRun > 2 {
+				continue
+			}
+		} else {
+			blankRun = 0
+		}
+
+		result = append(result, line)
+	}
+
+	normalizeFences(result, nil, parseFences(result))
+	return strings.Join(result, "\n")
+}
+
+// fenceBlock is a fenced code block, spanning lines open to close
+type fenceBlock struct {
+	open, close int // Close is -1 while the block is unclosed
+	char        byte
+	length      int
+	info        string
+	children    []*fenceBlock
+}
+
+// parseFenceLine reports the fence character, length and info string of a
+// fence line
+func parseFenceLine(line string) (char byte, length int, info string, ok bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(trimmed) < 3 || (trimmed[0] != '`' && trimmed[0] != '~') {
+		return 0, 0, "", false
+	}
+	char = trimmed[0]
+	for length < len(trimmed) && trimmed[length] == char {
+		length++
+	}
+	if length < 3 {
+		return 0, 0, "", false
+	}
+	info = strings.TrimSpace(trimmed[length:])
+	if char == '`' && strings.Contains(info, "`") {
+		return 0, 0, "", false
+	}
+	return char, length, info, true
+}
+
+// parseFences finds the fenced blocks in lines. Only markdown blocks, such
+// as the ````markdown wrapper around a response, hold nested blocks; a
+// fence line inside any other block is code.
+func parseFences(lines []string) []*fenceBlock {
+	var roots, stack []*fenceBlock
+	for i, line := range lines {
+		char, length, info, ok := parseFenceLine(line)
+		if !ok {
+			continue
+		}
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if char == top.char && length >= top.length && info == "" {
+				top.close = i
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			if lang := strings.ToLower(top.info); lang != "markdown" && lang != "md" {
+				continue
+			}
+		}
+
+		block := &fenceBlock{open: i, close: -1, char: char, length: length, info: info}
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			top.children = append(top.children, block)
+		} else {
+			roots = append(roots, block)
+		}
+		stack = append(stack, block)
+	}
+	return roots
+}
+
+// normalizeFences rewrites tilde fences as backtick fences, innermost first,
+// long enough that nothing inside closes them early. A block stays tilde
+// fenced when the backticks it needs would close its enclosing block.
+// Backtick closing fences are trimmed to the length of their opener.
+func normalizeFences(lines []string, parent *fenceBlock, blocks []*fenceBlock) {
+	for _, b := range blocks {
+		normalizeFences(lines, b, b.children)
+		if b.close == -1 {
+			continue
+		}
+
+		if b.char == '~' {
+			inner := strings.Join(lines[b.open+1:b.close], "\n")
+			fence := fenceFor(inner, max(b.length, 3))
+			if parent != nil && parent.char == '`' && len(fence) >= parent.length {
+				continue
+			}
+			b.char, b.length = '`', len(fence)
+			lines[b.open] = fenceIndent(lines[b.open]) + fence + b.info
+		}
+		lines[b.close] = fenceIndent(lines[b.close]) + strings.Repeat(string(b.char), b.length)
+	}
+}
+
+// fenceIndent returns the spaces before a fence
+func fenceIndent(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " "))]
+}
@@ -0,0 +1,55 @@
+package session
+
+import "testing"
+
+func TestNormalizeResponseFences(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "tilde fence becomes backticks",
+			in:   "~~~go\nx := 1\n~~~",
+			want: "```go\nx := 1\n```",
+		},
+		{
+			name: "nested block keeps the outer fence longer",
+			in:   "~~~~markdown\n```go\nx := 1\n```\n~~~~",
+			want: "````markdown\n```go\nx := 1\n```\n````",
+		},
+		{
+			name: "tildes stay when backticks would close the wrapper",
+			in:   "````markdown\n~~~~markdown\n```go\nx := 1\n```\n~~~~\n````",
+			want: "````markdown\n~~~~markdown\n```go\nx := 1\n```\n~~~~\n````",
+		},
+		{
+			name: "tilde block inside the wrapper",
+			in:   "````markdown\n~~~python\nprint(1)\n~~~\n````",
+			want: "````markdown\n```python\nprint(1)\n```\n````",
+		},
+		{
+			name: "long closing fence trimmed to its opener",
+			in:   "```go\nx := 1\n`````",
+			want: "```go\nx := 1\n```",
+		},
+		{
+			name: "tildes inside a code block are code",
+			in:   "```sh\n~~~\n```",
+			want: "```sh\n~~~\n```",
+		},
+		{
+			name: "unclosed tilde fence is left alone",
+			in:   "~~~go\nx := 1",
+			want: "~~~go\nx := 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeResponse(tt.in); got != tt.want {
+				t.Errorf("NormalizeResponse(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
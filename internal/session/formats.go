@@ -0,0 +1,238 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultFormat is the "# [N] Human" session format written by ask
+const DefaultFormat = "ask-md"
+
+// Format reads and writes the turns of a session file
+type Format interface {
+	Parse(content string) ([]Turn, error)
+	Render(turns []Turn) string
+
+	// Header is the text that starts a turn, without surrounding blank lines
+	Header(number int, role string) string
+}
+
+// formats holds the built-in session formats by name
+var formats = map[string]Format{
+	"ask-md":         askMarkdown{},
+	"frontmatter-md": frontMatterMarkdown{},
+	"numbered-md":    numberedMarkdown{},
+}
+
+// LookupFormat returns the format registered as name, treating "" as ask-md
+func LookupFormat(name string) (Format, error) {
+	if name == "" {
+		name = DefaultFormat
+	}
+	f, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown session format '%s' (use %s)", name, strings.Join(FormatNames(), ", "))
+	}
+	return f, nil
+}
+
+// FormatNames returns the registered format names in sorted order
+func FormatNames() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse extracts the turns of content written in the named format
+func Parse(content, format string) ([]Turn, error) {
+	f, err := LookupFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	return f.Parse(content)
+}
+
+// ParseAnyFormat parses content in the first format that finds turns in it,
+// trying ask-md first, for code that doesn't know the configured format
+func ParseAnyFormat(content string) ([]Turn, error) {
+	turns, err := ParseAllTurns(content)
+	if err == nil {
+		return turns, nil
+	}
+	for _, name := range FormatNames() {
+		if name == DefaultFormat {
+			continue
+		}
+		if turns, err := formats[name].Parse(content); err == nil {
+			return turns, nil
+		}
+	}
+	return nil, err
+}
+
+// askMarkdown is the native format with "# [N] Human" and "# [N] AI" headers
+type askMarkdown struct{}
+
+func (askMarkdown) Parse(content string) ([]Turn, error) { return ParseAllTurns(content) }
+func (askMarkdown) Render(turns []Turn) string           { return RenderTurns(turns) }
+
+func (askMarkdown) Header(number int, role string) string {
+	return fmt.Sprintf("# [%d] %s", number, role)
+}
+
+// frontMatterMarkdown starts each turn with a YAML front matter block:
+//
+//	---
+//	turn: 1
+//	role: human
+//	---
+type frontMatterMarkdown struct{}
+
+var frontMatterBlock = regexp.MustCompile(`(?m)^---[ \t]*\n((?:[A-Za-z_]+:.*\n)+)---[ \t]*(?:\n|$)`)
+
+func (frontMatterMarkdown) Parse(content string) ([]Turn, error) {
+	matches := frontMatterBlock.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no turns found in session")
+	}
+
+	var turns []Turn
+	for i, match := range matches {
+		fields := make(map[string]string)
+		for _, line := range strings.Split(strings.TrimSpace(content[match[2]:match[3]]), "\n") {
+			key, value, _ := strings.Cut(line, ":")
+			fields[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+
+		role, err := parseRole(fields["role"])
+		if err != nil {
+			return nil, fmt.Errorf("turn %d: %w", i+1, err)
+		}
+		number := i + 1
+		if n, err := strconv.Atoi(fields["turn"]); err == nil {
+			number = n
+		}
+
+		end := len(content)
+		if i < len(matches)-1 {
+			end = matches[i+1][0]
+		}
+		raw := content[match[1]:end]
+		turns = append(turns, Turn{Number: number, Role: role, Content: turnContent(raw, role), Raw: raw})
+	}
+	return turns, nil
+}
+
+func (f frontMatterMarkdown) Render(turns []Turn) string {
+	var b strings.Builder
+	for _, turn := range withOpenHumanTurn(turns) {
+		fmt.Fprintf(&b, "%s\n\n", f.Header(turn.Number, turn.Role))
+		if content := strings.TrimSpace(turn.Content); content != "" {
+			fmt.Fprintf(&b, "%s\n\n", content)
+		}
+	}
+	return b.String()
+}
+
+func (frontMatterMarkdown) Header(number int, role string) string {
+	return fmt.Sprintf("---\nturn: %d\nrole: %s\n---", number, strings.ToLower(role))
+}
+
+// numberedMarkdown marks turns with "User:" and "Assistant:" lines and
+// numbers them in order
+type numberedMarkdown struct{}
+
+var speakerLine = regexp.MustCompile(`(?m)^(User|Assistant):[ \t]*`)
+
+func (numberedMarkdown) Parse(content string) ([]Turn, error) {
+	matches := speakerLine.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no turns found in session")
+	}
+
+	var turns []Turn
+	for i, match := range matches {
+		role := "Human"
+		if content[match[2]:match[3]] == "Assistant" {
+			role = "AI"
+		}
+
+		end := len(content)
+		if i < len(matches)-1 {
+			end = matches[i+1][0]
+		}
+		raw := content[match[1]:end]
+		turns = append(turns, Turn{Number: i + 1, Role: role, Content: turnContent(raw, role), Raw: raw})
+	}
+	return turns, nil
+}
+
+func (f numberedMarkdown) Render(turns []Turn) string {
+	var b strings.Builder
+	for _, turn := range withOpenHumanTurn(turns) {
+		fmt.Fprintf(&b, "%s\n\n", f.Header(turn.Number, turn.Role))
+		if content := strings.TrimSpace(turn.Content); content != "" {
+			fmt.Fprintf(&b, "%s\n\n", content)
+		}
+	}
+	return b.String()
+}
+
+func (numberedMarkdown) Header(number int, role string) string {
+	if role == "AI" {
+		return "Assistant:"
+	}
+	return "User:"
+}
+
+// IsNative reports whether f is ask-md, the format whose headers the
+// session editing functions in this package find turns by
+func IsNative(f Format) bool {
+	_, ok := f.(askMarkdown)
+	return ok
+}
+
+// ReplaceTurnContent replaces the text of turn in content, found by its Raw
+// text, with text. It edits sessions in any format.
+func ReplaceTurnContent(content string, turn Turn, text string) string {
+	pos := strings.LastIndex(content, turn.Raw)
+	if pos == -1 || turn.Raw == "" {
+		return content
+	}
+	return content[:pos] + "\n\n" + strings.TrimSpace(text) + "\n\n" + content[pos+len(turn.Raw):]
+}
+
+// withOpenHumanTurn appends an empty Human turn after a final AI turn,
+// matching RenderTurns
+func withOpenHumanTurn(turns []Turn) []Turn {
+	if len(turns) == 0 || turns[len(turns)-1].Role != "AI" {
+		return turns
+	}
+	return append(turns[:len(turns):len(turns)], Turn{Number: turns[len(turns)-1].Number + 1, Role: "Human"})
+}
+
+// turnContent trims raw turn text, dropping the expansion comments chat
+// leaves in human turns
+func turnContent(raw, role string) string {
+	if role == "Human" {
+		return strings.TrimSpace(stripExpansionComments(raw))
+	}
+	return strings.TrimSpace(raw)
+}
+
+// parseRole maps the role names used by other formats to Human or AI
+func parseRole(role string) (string, error) {
+	switch strings.ToLower(role) {
+	case "human", "user":
+		return "Human", nil
+	case "ai", "assistant":
+		return "AI", nil
+	}
+	return "", fmt.Errorf("unknown role '%s'", role)
+}
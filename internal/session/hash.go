@@ -0,0 +1,54 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// ContentHash returns the SHA256 of content
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// FileHash returns the SHA256 of the file at path
+func FileHash(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return ContentHash(content), nil
+}
+
+// HashTurn returns the SHA256 of the raw content of turn n, or "" if not found
+func HashTurn(turns []Turn, n int) string {
+	for _, turn := range turns {
+		if turn.Number == n {
+			return rawHash(turn)
+		}
+	}
+	return ""
+}
+
+// rawHash returns the SHA256 of the raw content of turn
+func rawHash(turn Turn) string {
+	sum := sha256.Sum256([]byte(turn.Raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashLine formats a turn hash as "turn N role SHA256:<hash>"
+func HashLine(turn Turn, hash string) string {
+	return fmt.Sprintf("turn %d %s SHA256:%s", turn.Number, turn.Role, hash)
+}
+
+// HashLines returns a hash line for every turn, hashing each turn itself so
+// duplicate turn numbers don't share a hash
+func HashLines(turns []Turn) []string {
+	lines := make([]string, 0, len(turns))
+	for _, turn := range turns {
+		lines = append(lines, HashLine(turn, rawHash(turn)))
+	}
+	return lines
+}
@@ -0,0 +1,217 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Import formats supported by ParseImport
+const (
+	FormatOpenAIChat   = "openai-chat"
+	FormatClaudeExport = "claude-export"
+	FormatAskJSON      = "ask-json"
+)
+
+// ParseImport converts an exported conversation into turns
+func ParseImport(data []byte, format string) ([]Turn, error) {
+	var turns []Turn
+	var err error
+
+	switch format {
+	case FormatOpenAIChat:
+		turns, err = parseOpenAIChat(data)
+	case FormatClaudeExport:
+		turns, err = parseClaudeExport(data)
+	case FormatAskJSON:
+		turns, err = parseAskJSON(data)
+	default:
+		return nil, fmt.Errorf("unknown format '%s'. Use %s, %s or %s",
+			format, FormatOpenAIChat, FormatClaudeExport, FormatAskJSON)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Number turns sequentially
+	for i := range turns {
+		turns[i].Number = i + 1
+	}
+
+	if err := ValidateTurns(turns); err != nil {
+		return nil, err
+	}
+
+	return turns, nil
+}
+
+// ValidateTurns checks that turns start with Human and alternate roles
+func ValidateTurns(turns []Turn) error {
+	if len(turns) == 0 {
+		return fmt.Errorf("no turns found")
+	}
+
+	for i, turn := range turns {
+		expected := "Human"
+		if i%2 == 1 {
+			expected = "AI"
+		}
+		if turn.Role != expected {
+			return fmt.Errorf("turn %d is %s but expected %s (turns must alternate starting with Human)",
+				turn.Number, turn.Role, expected)
+		}
+		if strings.TrimSpace(turn.Content) == "" {
+			return fmt.Errorf("turn %d has no content", turn.Number)
+		}
+	}
+
+	return nil
+}
+
+// openAIMessage is a chat message in OpenAI's format
+type openAIMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// parseOpenAIChat parses {"messages": [...]} or a bare message array
+func parseOpenAIChat(data []byte) ([]Turn, error) {
+	var messages []openAIMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		var wrapper struct {
+			Messages []openAIMessage `json:"messages"`
+		}
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return nil, fmt.Errorf("invalid openai-chat JSON: %w", err)
+		}
+		messages = wrapper.Messages
+	}
+
+	var turns []Turn
+	for _, msg := range messages {
+		var role string
+		switch msg.Role {
+		case "user":
+			role = "Human"
+		case "assistant":
+			role = "AI"
+		default:
+			// System and tool messages have no session equivalent
+			continue
+		}
+
+		text, err := openAIText(msg.Content)
+		if err != nil {
+			return nil, err
+		}
+		turns = append(turns, Turn{Role: role, Content: text})
+	}
+
+	return turns, nil
+}
+
+// openAIText extracts text from string or content-part array values
+func openAIText(raw json.RawMessage) (string, error) {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", fmt.Errorf("unsupported message content: %w", err)
+	}
+
+	var texts []string
+	for _, part := range parts {
+		if part.Type == "text" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
+
+// claudeConversation is a conversation from a Claude.ai data export
+type claudeConversation struct {
+	Name         string `json:"name"`
+	ChatMessages []struct {
+		Sender  string `json:"sender"`
+		Text    string `json:"text"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"chat_messages"`
+}
+
+// parseClaudeExport parses a single conversation, or an export array holding
+// exactly one
+func parseClaudeExport(data []byte) ([]Turn, error) {
+	var conversation claudeConversation
+	if err := json.Unmarshal(data, &conversation); err != nil {
+		var conversations []claudeConversation
+		if err := json.Unmarshal(data, &conversations); err != nil {
+			return nil, fmt.Errorf("invalid claude-export JSON: %w", err)
+		}
+		if len(conversations) == 0 {
+			return nil, fmt.Errorf("no conversations in export")
+		}
+		if len(conversations) > 1 {
+			return nil, fmt.Errorf("export contains %d conversations. Extract one conversation to import", len(conversations))
+		}
+		conversation = conversations[0]
+	}
+
+	var turns []Turn
+	for _, msg := range conversation.ChatMessages {
+		var role string
+		switch msg.Sender {
+		case "human":
+			role = "Human"
+		case "assistant":
+			role = "AI"
+		default:
+			continue
+		}
+
+		text := msg.Text
+		if text == "" {
+			var texts []string
+			for _, part := range msg.Content {
+				if part.Type == "text" {
+					texts = append(texts, part.Text)
+				}
+			}
+			text = strings.Join(texts, "\n\n")
+		}
+		turns = append(turns, Turn{Role: role, Content: text})
+	}
+
+	return turns, nil
+}
+
+// parseAskJSON parses [{"role": "Human", "content": "..."}]
+func parseAskJSON(data []byte) ([]Turn, error) {
+	var entries []struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid ask-json: %w", err)
+	}
+
+	var turns []Turn
+	for _, entry := range entries {
+		switch entry.Role {
+		case "Human", "AI":
+		default:
+			return nil, fmt.Errorf("invalid role '%s'. Use Human or AI", entry.Role)
+		}
+		turns = append(turns, Turn{Role: entry.Role, Content: entry.Content})
+	}
+
+	return turns, nil
+}
@@ -0,0 +1,155 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// SessionIndex summarizes sessions so they can be searched without reading them
+type SessionIndex struct {
+	Sessions []IndexEntry `toml:"sessions"`
+}
+
+// IndexEntry is what the index knows about one session
+type IndexEntry struct {
+	Path            string    `toml:"path"` // Absolute path
+	LastModified    time.Time `toml:"last_modified"`
+	TurnCount       int       `toml:"turn_count"`
+	ModelsUsed      []string  `toml:"models_used"`
+	Tags            []string  `toml:"tags"`              // Session tags, then turn tags
+	FirstHumanWords string    `toml:"first_human_words"` // First 10 words of the first question
+}
+
+// LoadSessionIndex reads the index at path, returning an empty index if missing
+func LoadSessionIndex(path string) (*SessionIndex, error) {
+	index := &SessionIndex{}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return index, nil
+	}
+	if _, err := toml.DecodeFile(path, index); err != nil {
+		return index, fmt.Errorf("failed to decode session index: %w", err)
+	}
+	return index, nil
+}
+
+// Save writes the index to path, ordered by session path
+func (idx *SessionIndex) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	sort.Slice(idx.Sessions, func(i, j int) bool {
+		return idx.Sessions[i].Path < idx.Sessions[j].Path
+	})
+
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(idx); err != nil {
+		return err
+	}
+	return WriteAtomic(path, []byte(b.String()))
+}
+
+// UpdateSessionIndex loads the index at path, applies update and saves it,
+// holding a file lock so concurrent chats don't drop each other's entries
+func UpdateSessionIndex(path string, update func(*SessionIndex) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	unlock, err := LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	index, err := LoadSessionIndex(path)
+	if err != nil {
+		return err
+	}
+	if err := update(index); err != nil {
+		return err
+	}
+	if err := index.Save(path); err != nil {
+		return fmt.Errorf("failed to save session index: %w", err)
+	}
+	return nil
+}
+
+// Update replaces the entry with the same path, or adds it
+func (idx *SessionIndex) Update(entry IndexEntry) {
+	for i, e := range idx.Sessions {
+		if e.Path == entry.Path {
+			idx.Sessions[i] = entry
+			return
+		}
+	}
+	idx.Sessions = append(idx.Sessions, entry)
+}
+
+// Find returns the entry for the session at path
+func (idx *SessionIndex) Find(path string) (IndexEntry, bool) {
+	for _, e := range idx.Sessions {
+		if e.Path == path {
+			return e, true
+		}
+	}
+	return IndexEntry{}, false
+}
+
+// AddModels records models the session has used, skipping empty and known ones
+func (e *IndexEntry) AddModels(models ...string) {
+	for _, model := range models {
+		if model != "" && !slices.Contains(e.ModelsUsed, model) {
+			e.ModelsUsed = append(e.ModelsUsed, model)
+		}
+	}
+}
+
+// Search returns the entries matching every term, case-insensitively, in
+// their path, tags, models or first words, most recently modified first.
+// A non-empty tag must also be one of the entry's tags exactly.
+func (idx *SessionIndex) Search(terms []string, tag string) []IndexEntry {
+	var results []IndexEntry
+	for _, e := range idx.Sessions {
+		if tag != "" && !slices.Contains(e.Tags, tag) {
+			continue
+		}
+		text := strings.ToLower(strings.Join([]string{
+			e.Path,
+			strings.Join(e.Tags, " "),
+			strings.Join(e.ModelsUsed, " "),
+			e.FirstHumanWords,
+		}, " "))
+
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(text, strings.ToLower(term)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			results = append(results, e)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].LastModified.After(results[j].LastModified)
+	})
+	return results
+}
+
+// FirstWords returns the first n words of text on one line
+func FirstWords(text string, n int) string {
+	words := strings.Fields(text)
+	if len(words) > n {
+		words = words[:n]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,68 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var injectAnnotation = regexp.MustCompile(`<!--\s*inject:\s*(.*?)\s*-->[ \t]*\n?`)
+
+// ReadInjections returns the files named by <!-- inject: file --> annotations
+// in the front matter, in order
+func ReadInjections(content string) []string {
+	var files []string
+	for _, match := range injectAnnotation.FindAllStringSubmatch(frontMatter(content), -1) {
+		files = append(files, match[1])
+	}
+	return files
+}
+
+// AddInjection writes an inject annotation for file after any existing ones.
+// Content is returned unchanged when file is already injected.
+func AddInjection(content, file string) string {
+	front := frontMatter(content)
+	matches := injectAnnotation.FindAllStringSubmatchIndex(front, -1)
+	for _, loc := range matches {
+		if front[loc[2]:loc[3]] == file {
+			return content
+		}
+	}
+
+	line := fmt.Sprintf("<!-- inject: %s -->\n", file)
+	if len(matches) > 0 {
+		end := matches[len(matches)-1][1]
+		if !strings.HasSuffix(content[:end], "\n") {
+			line = "\n" + line
+		}
+		return content[:end] + line + content[end:]
+	}
+	return line + "\n" + content
+}
+
+// RemoveInjection drops the inject annotation for file, reporting whether
+// there was one
+func RemoveInjection(content, file string) (string, bool) {
+	front := frontMatter(content)
+	for _, loc := range injectAnnotation.FindAllStringSubmatchIndex(front, -1) {
+		if front[loc[2]:loc[3]] != file {
+			continue
+		}
+		// The blank line after the block goes with its first annotation
+		rest := content[loc[1]:]
+		if !injectAnnotation.MatchString(content[:loc[0]]) {
+			rest = strings.TrimLeft(rest, "\n")
+		}
+		return content[:loc[0]] + rest, true
+	}
+	return content, false
+}
+
+// InjectContext prepends context to turns as a human turn 0, which is sent
+// but never written to the session
+func InjectContext(turns []Turn, context string) []Turn {
+	if context == "" {
+		return turns
+	}
+	return append([]Turn{{Number: 0, Role: "Human", Content: context}}, turns...)
+}
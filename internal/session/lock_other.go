@@ -0,0 +1,9 @@
+//go:build !unix
+
+package session
+
+// LockFile is a no-op without flock; updates are only serialized within
+// one process
+func LockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,61 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Metadata holds per-session settings declared as HTML comments
+type Metadata struct {
+	Model string // From <!-- ask-model: haiku -->
+	Title string // From <!-- title: Exploring distributed systems -->
+}
+
+var (
+	modelAnnotation = regexp.MustCompile(`<!--\s*ask-model:\s*(\S+)\s*-->`)
+	titleAnnotation = regexp.MustCompile(`<!--\s*title:\s*(.*?)\s*-->`)
+)
+
+// ReadMetadata parses annotations that appear before the first turn
+func ReadMetadata(content string) Metadata {
+	var meta Metadata
+
+	if match := modelAnnotation.FindStringSubmatch(frontMatter(content)); match != nil {
+		meta.Model = match[1]
+	}
+	if match := titleAnnotation.FindStringSubmatch(frontMatter(content)); match != nil {
+		meta.Title = match[1]
+	}
+
+	return meta
+}
+
+// SetModel writes or updates the ask-model annotation at the top of the session
+func SetModel(content, model string) string {
+	return setAnnotation(content, modelAnnotation, fmt.Sprintf("<!-- ask-model: %s -->", model))
+}
+
+// SetTitle writes or updates the title annotation at the top of the session
+func SetTitle(content, title string) string {
+	title = strings.ReplaceAll(title, "-->", "")
+	return setAnnotation(content, titleAnnotation, fmt.Sprintf("<!-- title: %s -->", title))
+}
+
+// setAnnotation replaces the front matter annotation matching pattern, or prepends one
+func setAnnotation(content string, pattern *regexp.Regexp, annotation string) string {
+	front := frontMatter(content)
+	if loc := pattern.FindStringIndex(front); loc != nil {
+		return content[:loc[0]] + annotation + content[loc[1]:]
+	}
+
+	return annotation + "\n\n" + content
+}
+
+// frontMatter returns the text before the first turn header
+func frontMatter(content string) string {
+	if pos := strings.Index(content, "# ["); pos != -1 {
+		return content[:pos]
+	}
+	return content
+}
@@ -2,8 +2,10 @@ package session
 
 import (
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Turn represents a conversation turn
@@ -11,24 +13,88 @@ type Turn struct {
 	Number  int
 	Role    string // "Human" or "AI"
 	Content string
+	Raw     string // Unmodified text between this header and the next
+	Pinned  bool   // Marked (pin); kept when trimming to a token budget
+
+	// Set on AI turns recorded with timing enabled
+	Timestamp   time.Time // When the request was made
+	DurationSec int       // How long the response took to stream
+}
+
+var (
+	headerPattern     = regexp.MustCompile(`# \[(\d+)\] (Human|AI)((?: \((?:if:!?\w+|pin|\d{4}-\d\d-\d\dT[\d:]+Z)\))*)`)
+	headerAnnotations = regexp.MustCompile(`\((if:(!?)(\w+)|pin|\d{4}-\d\d-\d\dT[\d:]+Z)\)`)
+)
+
+// headerAnnotation is what the parenthesized annotations after a turn header say
+type headerAnnotation struct {
+	condition string // Variable of (if:VAR) or (if:!VAR)
+	negate    bool
+	pinned    bool
+	timestamp string
+}
+
+// parseHeaderAnnotations reads the annotations after a turn header, which
+// may come in any order
+func parseHeaderAnnotations(text string) headerAnnotation {
+	var a headerAnnotation
+	for _, m := range headerAnnotations.FindAllStringSubmatch(text, -1) {
+		switch {
+		case m[3] != "":
+			a.condition = m[3]
+			a.negate = m[2] == "!"
+		case m[1] == "pin":
+			a.pinned = true
+		default:
+			a.timestamp = m[1]
+		}
+	}
+	return a
 }
 
-// ParseAllTurns extracts all turns from the session
+// ParseAllTurns extracts all turns from the session.
+// Turns annotated with (if:VAR) are excluded unless VAR is set and non-empty;
+// (if:!VAR) inverts the check. Excluding a Human turn also excludes its AI response.
+// A (pin) annotation on a Human turn pins it and its AI response.
 func ParseAllTurns(content string) ([]Turn, error) {
 	var turns []Turn
 
-	// Pattern to match both Human and AI headers
-	pattern := regexp.MustCompile(`# \[(\d+)\] (Human|AI)`)
-	matches := pattern.FindAllStringSubmatchIndex(content, -1)
+	// Pattern to match both Human and AI headers with annotations in any order
+	matches := headerPattern.FindAllStringSubmatchIndex(content, -1)
 
 	if len(matches) == 0 {
 		return nil, fmt.Errorf("no turns found in session")
 	}
 
+	skipResponse := false
+	pinResponse := false
 	for i, match := range matches {
 		turnNumber := parseIntOrZero(content[match[2]:match[3]])
 		role := content[match[4]:match[5]]
 
+		annotations := parseHeaderAnnotations(content[match[6]:match[7]])
+
+		// Evaluate the optional condition
+		included := true
+		if annotations.condition != "" {
+			included = (os.Getenv(annotations.condition) != "") != annotations.negate
+		}
+
+		pinned := annotations.pinned
+		if role == "Human" {
+			skipResponse = !included
+			pinResponse = pinned
+		} else {
+			if skipResponse {
+				included = false
+			}
+			pinned = pinned || pinResponse
+		}
+
+		if !included {
+			continue
+		}
+
 		// Extract content from after header to next header or EOF
 		startPos := match[1] // End of the match
 		var endPos int
@@ -41,37 +107,70 @@ func ParseAllTurns(content string) ([]Turn, error) {
 			endPos = len(content)
 		}
 
-		turnContent := strings.TrimSpace(content[startPos:endPos])
+		raw := content[startPos:endPos]
+		turnContent := strings.TrimSpace(raw)
 
-		// For AI turns, strip the markdown wrapper
+		// For AI turns, strip the timing comment and markdown wrapper
+		var timestamp time.Time
+		var durationSec int
 		if role == "AI" {
+			if annotations.timestamp != "" {
+				timestamp, _ = time.Parse(time.RFC3339, annotations.timestamp)
+			}
+			turnContent, durationSec = parseDurationComment(turnContent)
 			turnContent = stripMarkdownWrapper(turnContent)
+		} else {
+			turnContent = strings.TrimSpace(stripExpansionComments(turnContent))
 		}
 
 		turns = append(turns, Turn{
 			Number:  turnNumber,
 			Role:    role,
 			Content: turnContent,
+			Raw:     raw,
+			Pinned:  pinned,
+
+			Timestamp:   timestamp,
+			DurationSec: durationSec,
 		})
 	}
 
+	if len(turns) == 0 {
+		return nil, fmt.Errorf("all turns are excluded by their conditions")
+	}
+
 	return turns, nil
 }
 
-// stripMarkdownWrapper removes ````markdown wrapper from AI responses
+// LastTurnNumber returns the highest turn number in the session, including
+// turns excluded by conditions
+func LastTurnNumber(content string) int {
+	pattern := regexp.MustCompile(`# \[(\d+)\] (?:Human|AI)`)
+
+	last := 0
+	for _, match := range pattern.FindAllStringSubmatch(content, -1) {
+		if n := parseIntOrZero(match[1]); n > last {
+			last = n
+		}
+	}
+	return last
+}
+
+// stripMarkdownWrapper removes the ````markdown wrapper from AI responses,
+// whatever number of backticks the fence uses
 func stripMarkdownWrapper(content string) string {
 	// Remove leading ````markdown
-	if strings.HasPrefix(content, "````markdown\n") {
-		content = strings.TrimPrefix(content, "````markdown\n")
-	} else if strings.HasPrefix(content, "````markdown") {
-		content = strings.TrimPrefix(content, "````markdown")
+	fence := strings.Repeat("`", DefaultFenceDepth)
+	if match := markdownFence.FindStringSubmatch(content); match != nil {
+		content = content[len(match[0]):]
+		fence = match[1]
 	}
 
-	// Remove trailing ````
-	if strings.HasSuffix(content, "\n````") {
-		content = strings.TrimSuffix(content, "\n````")
-	} else if strings.HasSuffix(content, "````") {
-		content = strings.TrimSuffix(content, "````")
+	// Remove the matching trailing fence
+	if strings.HasSuffix(content, "\n"+fence) {
+		content = strings.TrimSuffix(content, "\n"+fence)
+	} else if strings.HasSuffix(content, fence) {
+		content = strings.TrimSuffix(content, fence)
 	}
 
 	return strings.TrimSpace(content)
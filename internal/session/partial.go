@@ -0,0 +1,50 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// RecoverPartial rebuilds an unfinished AI turn from the session's partial
+// file, closes it, and opens the next Human turn. It returns the recovered
+// turn number and removes the partial file.
+func RecoverPartial(path string) (int, error) {
+	partialPath := PartialPath(path)
+	partial, err := os.ReadFile(partialPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, fmt.Errorf("no partial response found (%s)", partialPath)
+		}
+		return 0, fmt.Errorf("failed to read %s: %w", partialPath, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	// An unfinished response is an AI header with no Human header after it
+	pattern := regexp.MustCompile(`(?m)^# \[(\d+)\] (Human|AI)`)
+	matches := pattern.FindAllSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("no turns found in %s", path)
+	}
+	last := matches[len(matches)-1]
+	if string(content[last[4]:last[5]]) != "AI" {
+		return 0, fmt.Errorf("%s has no unfinished response; delete %s to discard it", path, partialPath)
+	}
+	turnNumber := parseIntOrZero(string(content[last[2]:last[3]]))
+
+	recovered := strings.TrimRight(string(content[:last[0]]), "\n ") + "\n"
+	recovered = AppendAIResponse(recovered, turnNumber, string(partial)+"\n[Recovered from partial file]")
+	recovered += fmt.Sprintf("\n# [%d] Human\n\n", turnNumber+1)
+
+	if err := WriteAtomic(path, []byte(recovered)); err != nil {
+		return 0, fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	os.Remove(partialPath)
+
+	return turnNumber, nil
+}
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// turnHeader matches the number and role of a turn header at the start of a line
+var turnHeader = regexp.MustCompile(`(?m)^# \[(\d+)\] (Human|AI)`)
+
+// TurnMove records the number a turn had before and after renumbering
+type TurnMove struct {
+	Old, New int
+	Role     string
+}
+
+// RenumberTurns rewrites the turn headers of the session at path so they
+// count from 1 in file order, keeping annotations after the role. Turn tags
+// and expansion records in the front matter follow their turns. It returns
+// every turn in file order, with its old and new number; the file is only
+// written when a number changed.
+func RenumberTurns(path string) ([]TurnMove, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	renumbered, moves := RenumberContent(string(content))
+	if len(moves) == 0 {
+		return nil, fmt.Errorf("no turns found in %s", path)
+	}
+	if renumbered == string(content) {
+		return moves, nil
+	}
+
+	if err := WriteAtomic(path, []byte(renumbered)); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return moves, nil
+}
+
+// RenumberContent is RenumberTurns for session content. Expanded section
+// headings like "## [3.1] file" follow the turn they sit in. When two turns
+// shared a number, front matter annotations follow the first.
+func RenumberContent(content string) (string, []TurnMove) {
+	var moves []TurnMove
+	newNumber := make(map[int]int)
+	var body strings.Builder
+	headers := turnHeader.FindAllStringSubmatchIndex(content, -1)
+	if len(headers) > 0 {
+		body.WriteString(content[:headers[0][0]])
+	}
+	for i, loc := range headers {
+		old, _ := strconv.Atoi(content[loc[2]:loc[3]])
+		role := content[loc[4]:loc[5]]
+		moves = append(moves, TurnMove{Old: old, New: i + 1, Role: role})
+		if _, seen := newNumber[old]; !seen {
+			newNumber[old] = i + 1
+		}
+
+		end := len(content)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		fmt.Fprintf(&body, "# [%d] %s", i+1, role)
+		body.WriteString(renumberSections(content[loc[1]:end], old, i+1))
+	}
+	if len(headers) == 0 {
+		body.WriteString(content)
+	}
+
+	// Front matter ends at the first header, which the rewrite doesn't move
+	renumbered := body.String()
+	end := len(frontMatter(renumbered))
+	front := renumbered[:end]
+	renumber := func(pattern *regexp.Regexp) {
+		front = pattern.ReplaceAllStringFunc(front, func(annotation string) string {
+			loc := pattern.FindStringSubmatchIndex(annotation)
+			old, _ := strconv.Atoi(annotation[loc[2]:loc[3]])
+			n, ok := newNumber[old]
+			if !ok {
+				return annotation
+			}
+			return annotation[:loc[2]] + strconv.Itoa(n) + annotation[loc[3]:]
+		})
+	}
+	renumber(turnTagsAnnotation)
+	renumber(expansionRecord)
+
+	return front + renumbered[end:], moves
+}
+
+// sectionPrefix matches the turn number of an expanded section heading
+var sectionPrefix = regexp.MustCompile(`(?m)^(#+ \[)(\d+)(\.\d+)`)
+
+// renumberSections moves the section headings of turn old in text to turn n
+func renumberSections(text string, old, n int) string {
+	if old == n {
+		return text
+	}
+	return sectionPrefix.ReplaceAllStringFunc(text, func(heading string) string {
+		match := sectionPrefix.FindStringSubmatch(heading)
+		if number, _ := strconv.Atoi(match[2]); number != old {
+			return heading
+		}
+		return match[1] + strconv.Itoa(n) + match[3]
+	})
+}
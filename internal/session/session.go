@@ -1,18 +1,84 @@
 package session
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 )
 
 // WriteAtomic writes content to file atomically
 func WriteAtomic(path string, content []byte) error {
-	tmp := path + ".tmp"
-	if err := os.WriteFile(tmp, content, 0644); err != nil {
+	tmp, err := writeTemp(path, content)
+	if err != nil {
 		return err
 	}
-	return os.Rename(tmp, path)
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// writeTemp writes content to a uniquely named file beside path, so
+// concurrent writers of the same file never share a temporary file
+func writeTemp(path string, content []byte) (string, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Chmod(0644)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// ErrModified means a file changed on disk after it was read
+var ErrModified = errors.New("modified by another process")
+
+// WriteIfUnchanged writes content atomically, but only if the file at path
+// still has the hash it had when read. An empty hash skips the check.
+func WriteIfUnchanged(path string, content []byte, hash string) error {
+	tmp, err := writeTemp(path, content)
+	if err != nil {
+		return err
+	}
+	// Checked after the slow write so the window before the rename is short
+	if err := CheckUnchanged(path, hash); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// CheckUnchanged returns ErrModified if the file at path no longer has hash.
+// An empty hash skips the check.
+func CheckUnchanged(path, hash string) error {
+	if hash == "" {
+		return nil
+	}
+	current, err := FileHash(path)
+	if err != nil {
+		return err
+	}
+	if current != hash {
+		return ErrModified
+	}
+	return nil
 }
 
 // ReplaceLastHumanTurn replaces the last human turn with expanded content
@@ -25,6 +91,11 @@ func ReplaceLastHumanTurn(content string, turnNumber int, expanded string) strin
 		return content
 	}
 
+	// Keep any annotation on the rest of the header line
+	if lineEnd := strings.Index(content[headerPos:], "\n"); lineEnd != -1 {
+		header = content[headerPos : headerPos+lineEnd]
+	}
+
 	// Find the next header (if any)
 	afterHeader := content[headerPos+len(header):]
 	nextHeaderPos := strings.Index(afterHeader, "\n# [")
@@ -42,8 +113,67 @@ func ReplaceLastHumanTurn(content string, turnNumber int, expanded string) strin
 	return result
 }
 
+// RenderTurns renders turns in session.md format, ending with an open Human turn
+func RenderTurns(turns []Turn) string {
+	var b strings.Builder
+
+	for _, turn := range turns {
+		if turn.Role == "AI" {
+			fence := fenceFor(turn.Content, DefaultFenceDepth)
+			fmt.Fprintf(&b, "# [%d] AI\n\n%smarkdown\n%s\n%s\n\n", turn.Number, fence, strings.TrimSpace(turn.Content), fence)
+		} else {
+			fmt.Fprintf(&b, "# [%d] Human\n\n%s\n\n", turn.Number, strings.TrimSpace(turn.Content))
+		}
+	}
+
+	// Leave a fresh Human turn ready for the next question
+	if len(turns) > 0 && turns[len(turns)-1].Role == "AI" {
+		fmt.Fprintf(&b, "# [%d] Human\n\n", turns[len(turns)-1].Number+1)
+	}
+
+	return b.String()
+}
+
 // AppendAIResponse appends an AI response to the session
 func AppendAIResponse(content string, turnNumber int, response string) string {
-	aiSection := fmt.Sprintf("\n# [%d] AI\n\n````markdown\n%s\n````\n", turnNumber, strings.TrimSpace(response))
+	fence := fenceFor(response, DefaultFenceDepth)
+	aiSection := fmt.Sprintf("\n# [%d] AI\n\n%smarkdown\n%s\n%s\n", turnNumber, fence, strings.TrimSpace(response), fence)
 	return content + aiSection
 }
+
+// TruncateAfterTurn rewrites the session to end after turn n.
+// The original file is kept as path.bak so discarded turns can be recovered.
+func TruncateAfterTurn(path string, n int) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	pattern := regexp.MustCompile(`(?m)^# \[(\d+)\] (?:Human|AI)`)
+	cut := -1
+	found := false
+	for _, match := range pattern.FindAllSubmatchIndex(content, -1) {
+		number := parseIntOrZero(string(content[match[2]:match[3]]))
+		if number == n {
+			found = true
+		}
+		if number > n {
+			cut = match[0]
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("turn %d not found in %s", n, path)
+	}
+	if cut == -1 {
+		return nil
+	}
+
+	if err := os.WriteFile(path+".bak", content, 0644); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	truncated := strings.TrimRight(string(content[:cut]), "\n ") + "\n"
+	return WriteAtomic(path, []byte(truncated))
+}
@@ -3,18 +3,39 @@ package session
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
+// maxStreamBuffer is the largest batch SetBuffering accepts
+const maxStreamBuffer = 1024 * 1024
+
 // StreamWriter handles streaming writes to session.md
 type StreamWriter struct {
 	file           *os.File
 	writer         *bufio.Writer
+	out            io.Writer // Where chunks go: writer plus any extra sinks
 	turnNumber     int
 	headerWritten  bool // Track if we've written the AI header
 	contentWritten bool // Track if any actual content was written
 	isInterrupted  bool
+	partial        *os.File // Mirror of streamed chunks, see EnablePartialFile
+	partialPath    string
+	timing         bool      // Record start time and duration, see EnableTiming
+	startedAt      time.Time // When the request was made
+	startOffset    int64     // Session size before the response, see Rewind
+	fenceDepth     int       // Backticks around the response, see SetFenceDepth
+	backtickRun    int       // Backticks at the end of the content so far
+	longestRun     int       // Longest run of backticks in the content
+	format         Format    // Format of the turn headers, see SetFormat
+
+	// Chunks are batched before writing, see SetBuffering
+	bufferLimit   int
+	writeInterval time.Duration
+	lastFlush     time.Time
 }
 
 // NewStreamWriter creates a new streaming writer for the AI response
@@ -25,12 +46,24 @@ func NewStreamWriter(path string, turnNumber int) (*StreamWriter, error) {
 		return nil, fmt.Errorf("failed to open session for writing: %w", err)
 	}
 
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat session: %w", err)
+	}
+
+	writer := bufio.NewWriterSize(file, maxStreamBuffer)
 	return &StreamWriter{
 		file:           file,
-		writer:         bufio.NewWriter(file),
+		writer:         writer,
+		out:            writer,
 		turnNumber:     turnNumber,
 		headerWritten:  false,
 		contentWritten: false,
+		startedAt:      time.Now(),
+		startOffset:    info.Size(),
+		fenceDepth:     DefaultFenceDepth,
+		format:         askMarkdown{},
 	}, nil
 }
 
@@ -40,7 +73,15 @@ func (sw *StreamWriter) writeHeader() error {
 		return nil
 	}
 
-	header := fmt.Sprintf("\n\n# [%d] AI\n\n````markdown\n", sw.turnNumber)
+	fence := strings.Repeat("`", sw.fenceDepth)
+	header := fmt.Sprintf("\n\n# [%d] AI\n\n%smarkdown\n", sw.turnNumber, fence)
+	if sw.timing {
+		header = fmt.Sprintf("\n\n# [%d] AI (%s)\n\n%smarkdown\n",
+			sw.turnNumber, sw.startedAt.UTC().Format(time.RFC3339), fence)
+	}
+	if !IsNative(sw.format) {
+		header = "\n\n" + sw.format.Header(sw.turnNumber, "AI") + "\n\n"
+	}
 	if _, err := sw.writer.WriteString(header); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
 	}
@@ -72,20 +113,152 @@ func (sw *StreamWriter) WriteChunk(chunk string) error {
 		}
 	}
 
-	if _, err := sw.writer.WriteString(chunk); err != nil {
+	if _, err := io.WriteString(sw.out, chunk); err != nil {
 		return fmt.Errorf("failed to write chunk: %w", err)
 	}
 
 	sw.contentWritten = true
+	sw.trackBackticks(chunk)
 
-	// Flush after each chunk for immediate visibility
+	// Without buffering, flush after each chunk for immediate visibility
+	if sw.writer.Buffered() < sw.bufferLimit && time.Since(sw.lastFlush) < sw.writeInterval {
+		return nil
+	}
+	sw.lastFlush = time.Now()
 	return sw.writer.Flush()
 }
 
+// SetBuffering batches chunks until limit bytes are waiting or interval
+// has passed since the last write, so the session isn't written for every
+// few tokens. Tee writers still get each chunk as it arrives. The interval
+// is checked when a chunk arrives; Close writes whatever is left.
+func (sw *StreamWriter) SetBuffering(limit int, interval time.Duration) {
+	sw.bufferLimit = min(limit, maxStreamBuffer)
+	sw.writeInterval = interval
+	sw.lastFlush = time.Now()
+}
+
+// EnablePartialFile mirrors each chunk to path so an interrupted response
+// can be recovered with 'ask resume'
+func (sw *StreamWriter) EnablePartialFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create partial file: %w", err)
+	}
+	sw.partial = file
+	sw.partialPath = path
+	sw.Tee(file)
+	return nil
+}
+
+// SetFormat writes turn headers in format f. Only ask-md responses are
+// wrapped in a fence or carry a start time in their header.
+func (sw *StreamWriter) SetFormat(f Format) {
+	sw.format = f
+}
+
+// SetFenceDepth sets how many backticks wrap the response; fewer than 3
+// is ignored. The fence is widened when the response needs it, see Close.
+func (sw *StreamWriter) SetFenceDepth(depth int) {
+	if depth >= 3 {
+		sw.fenceDepth = depth
+	}
+}
+
+// trackBackticks updates the longest backtick run with chunk, counting
+// runs that continue from the previous chunk
+func (sw *StreamWriter) trackBackticks(chunk string) {
+	for i := 0; i < len(chunk); i++ {
+		if chunk[i] != '`' {
+			sw.backtickRun = 0
+			continue
+		}
+		sw.backtickRun++
+		if sw.backtickRun > sw.longestRun {
+			sw.longestRun = sw.backtickRun
+		}
+	}
+}
+
+// widenFence rewrites the opening fence of the response with depth
+// backticks. The file is opened for appending, so the response is read
+// back, truncated and appended again.
+func (sw *StreamWriter) widenFence(depth int) error {
+	if err := sw.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush session: %w", err)
+	}
+	content, err := os.ReadFile(sw.file.Name())
+	if err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+
+	response := string(content[sw.startOffset:])
+	old := strings.Repeat("`", sw.fenceDepth) + "markdown\n"
+	response = strings.Replace(response, old, strings.Repeat("`", depth)+"markdown\n", 1)
+
+	if err := sw.file.Truncate(sw.startOffset); err != nil {
+		return fmt.Errorf("failed to truncate session: %w", err)
+	}
+	if _, err := sw.writer.WriteString(response); err != nil {
+		return fmt.Errorf("failed to rewrite response: %w", err)
+	}
+	sw.fenceDepth = depth
+	return nil
+}
+
+// EnableTiming adds the request time to the AI header and a
+// <!-- duration: 47s --> comment after the response
+func (sw *StreamWriter) EnableTiming() {
+	sw.timing = true
+}
+
+// Rewind discards everything written so far, so a dropped response can be
+// streamed again. Chunks already sent to a Tee writer can't be taken back.
+func (sw *StreamWriter) Rewind() error {
+	// Batched chunks belong to the dropped response, so they are never written
+	sw.writer.Reset(sw.file)
+	sw.lastFlush = time.Time{}
+
+	if err := sw.file.Truncate(sw.startOffset); err != nil {
+		return fmt.Errorf("failed to truncate session: %w", err)
+	}
+	if _, err := sw.file.Seek(sw.startOffset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek session: %w", err)
+	}
+
+	if sw.partial != nil {
+		if err := sw.partial.Truncate(0); err != nil {
+			return fmt.Errorf("failed to truncate partial file: %w", err)
+		}
+		if _, err := sw.partial.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek partial file: %w", err)
+		}
+	}
+
+	sw.headerWritten = false
+	sw.contentWritten = false
+	sw.backtickRun = 0
+	sw.longestRun = 0
+	return nil
+}
+
+// Tee also sends each chunk to w, without the headers and fences
+// that only belong in the session file
+func (sw *StreamWriter) Tee(w io.Writer) {
+	sw.out = io.MultiWriter(sw.out, w)
+}
+
 // Close finalizes the streaming session
 func (sw *StreamWriter) Close(interrupted bool, tokenCount int) error {
 	defer sw.file.Close()
 
+	// The session is about to be finalized, so the partial copy is no longer
+	// needed. It only survives if the process dies before reaching here.
+	if sw.partial != nil {
+		sw.partial.Close()
+		os.Remove(sw.partialPath)
+	}
+
 	// If nothing was written at all, just close and return
 	if !sw.headerWritten {
 		return nil
@@ -99,12 +272,26 @@ func (sw *StreamWriter) Close(interrupted bool, tokenCount int) error {
 		sw.writer.WriteString(marker)
 	}
 
-	// Close markdown fence (only if we opened it)
+	// Close markdown fence (only if we opened it), first widening it if
+	// the response contains a fence as long as the wrapper
 	if sw.headerWritten {
-		sw.writer.WriteString("\n````\n")
+		if IsNative(sw.format) {
+			if sw.longestRun >= sw.fenceDepth {
+				if err := sw.widenFence(sw.longestRun + 1); err != nil {
+					return err
+				}
+			}
+			sw.writer.WriteString("\n" + strings.Repeat("`", sw.fenceDepth) + "\n")
+		} else {
+			sw.writer.WriteString("\n")
+		}
+
+		if sw.timing {
+			fmt.Fprintf(sw.writer, "%s\n", durationComment(time.Since(sw.startedAt)))
+		}
 
 		// Only add next Human turn if we wrote AI content
-		nextTurn := fmt.Sprintf("\n\n# [%d] Human\n\n", sw.turnNumber+1)
+		nextTurn := "\n\n" + sw.format.Header(sw.turnNumber+1, "Human") + "\n\n"
 		sw.writer.WriteString(nextTurn)
 	}
 
@@ -141,3 +328,9 @@ func StreamResponse(path string, turnNumber int, streamFunc func(*StreamWriter)
 
 	return nil
 }
+
+// PartialPath returns the partial file for a session, e.g. session.partial.md
+func PartialPath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".partial" + ext
+}
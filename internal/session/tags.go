@@ -0,0 +1,218 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	tagsAnnotation     = regexp.MustCompile(`<!--\s*tags:\s*(.*?)\s*-->[ \t]*\n?`)
+	turnTagsAnnotation = regexp.MustCompile(`<!--\s*turn-tags:\s*(\d+):\s*(.*?)\s*-->[ \t]*\n?`)
+	validTag           = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+)
+
+// NormalizeTag lowercases tag and checks it only uses letters, digits, '.', '_' and '-'
+func NormalizeTag(tag string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(tag))
+	if !validTag.MatchString(normalized) {
+		return "", fmt.Errorf("invalid tag '%s': use letters, digits, '.', '_' and '-'", tag)
+	}
+	return normalized, nil
+}
+
+// ReadTags returns the tags of turn, or of the whole session when turn is 0,
+// from <!-- tags: a, b --> and <!-- turn-tags: 5: a, b --> annotations
+func ReadTags(content string, turn int) []string {
+	front := frontMatter(content)
+	if turn == 0 {
+		if match := tagsAnnotation.FindStringSubmatch(front); match != nil {
+			return splitTags(match[1])
+		}
+		return nil
+	}
+
+	for _, match := range turnTagsAnnotation.FindAllStringSubmatch(front, -1) {
+		if n, _ := strconv.Atoi(match[1]); n == turn {
+			return splitTags(match[2])
+		}
+	}
+	return nil
+}
+
+// AllTags returns the tags of the session followed by those of its turns,
+// each once
+func AllTags(content string) []string {
+	tags := ReadTags(content, 0)
+	for _, match := range turnTagsAnnotation.FindAllStringSubmatch(frontMatter(content), -1) {
+		for _, tag := range splitTags(match[2]) {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// SetTags replaces the tags of turn, or of the session when turn is 0.
+// An empty list removes the annotation.
+func SetTags(content string, turn int, tags []string) string {
+	pattern := tagsAnnotation
+	annotation := fmt.Sprintf("<!-- tags: %s -->", strings.Join(tags, ", "))
+	var loc []int
+	if turn == 0 {
+		loc = pattern.FindStringIndex(frontMatter(content))
+	} else {
+		pattern = turnTagsAnnotation
+		annotation = fmt.Sprintf("<!-- turn-tags: %d: %s -->", turn, strings.Join(tags, ", "))
+		front := frontMatter(content)
+		for _, match := range pattern.FindAllStringSubmatchIndex(front, -1) {
+			if n, _ := strconv.Atoi(front[match[2]:match[3]]); n == turn {
+				loc = match[:2]
+				break
+			}
+		}
+	}
+
+	switch {
+	case loc != nil && len(tags) == 0:
+		return content[:loc[0]] + strings.TrimLeft(content[loc[1]:], "\n")
+	case loc != nil:
+		return content[:loc[0]] + annotation + "\n" + content[loc[1]:]
+	case len(tags) == 0:
+		return content
+	default:
+		return annotation + "\n\n" + content
+	}
+}
+
+func splitTags(list string) []string {
+	var tags []string
+	for _, tag := range strings.Split(list, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// TagIndex maps tags to the sessions and turns that carry them
+type TagIndex struct {
+	Entries []TagEntry `toml:"entries"`
+}
+
+// TagEntry records one tag on a session, or on one of its turns
+type TagEntry struct {
+	Tag     string `toml:"tag"`
+	Session string `toml:"session"`        // Absolute path
+	Turn    int    `toml:"turn,omitempty"` // 0 for the whole session
+}
+
+// LoadTagIndex reads the index at path, returning an empty index if missing
+func LoadTagIndex(path string) (*TagIndex, error) {
+	index := &TagIndex{}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return index, nil
+	}
+	if _, err := toml.DecodeFile(path, index); err != nil {
+		return index, fmt.Errorf("failed to decode tag index: %w", err)
+	}
+	return index, nil
+}
+
+// Save writes the index to path
+func (idx *TagIndex) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	sort.Slice(idx.Entries, func(i, j int) bool {
+		a, b := idx.Entries[i], idx.Entries[j]
+		if a.Tag != b.Tag {
+			return a.Tag < b.Tag
+		}
+		if a.Session != b.Session {
+			return a.Session < b.Session
+		}
+		return a.Turn < b.Turn
+	})
+
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(idx); err != nil {
+		return err
+	}
+	return WriteAtomic(path, []byte(b.String()))
+}
+
+// UpdateTagIndex loads the index at path, applies update and saves it,
+// holding a file lock so concurrent tag commands don't drop each other's entries
+func UpdateTagIndex(path string, update func(*TagIndex) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	unlock, err := LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	index, err := LoadTagIndex(path)
+	if err != nil {
+		return err
+	}
+	if err := update(index); err != nil {
+		return err
+	}
+	if err := index.Save(path); err != nil {
+		return fmt.Errorf("failed to save tag index: %w", err)
+	}
+	return nil
+}
+
+// Add records entry unless it is already present
+func (idx *TagIndex) Add(entry TagEntry) {
+	for _, e := range idx.Entries {
+		if e == entry {
+			return
+		}
+	}
+	idx.Entries = append(idx.Entries, entry)
+}
+
+// Remove drops entry, reporting whether it was present
+func (idx *TagIndex) Remove(entry TagEntry) bool {
+	for i, e := range idx.Entries {
+		if e == entry {
+			idx.Entries = append(idx.Entries[:i], idx.Entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the entries for tag
+func (idx *TagIndex) Find(tag string) []TagEntry {
+	var entries []TagEntry
+	for _, e := range idx.Entries {
+		if e.Tag == tag {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Counts returns how many sessions and turns carry each tag
+func (idx *TagIndex) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, e := range idx.Entries {
+		counts[e.Tag]++
+	}
+	return counts
+}
@@ -0,0 +1,29 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationPattern matches the comment StreamWriter appends after a timed response
+var durationPattern = regexp.MustCompile(`(?m)^<!-- duration: (\d+)s -->\s*$`)
+
+// durationComment renders a response duration, rounded to whole seconds
+func durationComment(d time.Duration) string {
+	return fmt.Sprintf("<!-- duration: %ds -->", int(d.Round(time.Second).Seconds()))
+}
+
+// parseDurationComment removes the duration comment from an AI turn,
+// returning the remaining content and the duration in seconds
+func parseDurationComment(content string) (string, int) {
+	match := durationPattern.FindStringSubmatchIndex(content)
+	if match == nil {
+		return content, 0
+	}
+
+	seconds, _ := strconv.Atoi(content[match[2]:match[3]])
+	return strings.TrimSpace(content[:match[0]] + content[match[1]:]), seconds
+}
@@ -0,0 +1,21 @@
+package verbose
+
+import "fmt"
+
+// Level is how much status output a command prints
+type Level int
+
+const (
+	Silent  Level = iota // Nothing but the session file
+	Minimal              // The final token count
+	Normal               // Model, expansion stats and streaming progress
+	Verbose              // Also profile ARN, request IDs, session path and timings
+)
+
+// Parse converts a --verbosity value, which must be 0 to 3
+func Parse(n int) (Level, error) {
+	if n < int(Silent) || n > int(Verbose) {
+		return Normal, fmt.Errorf("verbosity %d must be between 0 and 3", n)
+	}
+	return Level(n), nil
+}
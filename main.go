@@ -9,6 +9,9 @@ import (
 
 	"github.com/alecthomas/kong"
 	"github.com/rana/ask/cmd"
+	"github.com/rana/ask/internal/bedrock"
+	"github.com/rana/ask/internal/config"
+	"github.com/rana/ask/internal/verbose"
 	"github.com/rana/ask/internal/version"
 )
 
@@ -43,9 +46,30 @@ func main() {
 		kong.UsageOnError(),
 	)
 
+	// Point config at --config (or ASK_CONFIG) before any command loads it
+	if cli.Config != "" {
+		config.SetConfigPath(cli.Config)
+	}
+
+	// --quiet and --verbose are shorthands for the ends of --verbosity
+	level, err := verbose.Parse(cli.Verbosity)
+	kongCtx.FatalIfErrorf(err)
+	switch {
+	case cli.Quiet:
+		level = verbose.Silent
+	case cli.Verbose:
+		level = verbose.Verbose
+	}
+	bedrock.SetVerbose(level >= verbose.Verbose)
+
 	// Bind the context for commands to use
 	kongCtx.Bind(ctx)
 
-	err := kongCtx.Run(&cmd.Context{Context: ctx})
+	err = kongCtx.Run(&cmd.Context{
+		Context:       ctx,
+		Quiet:         cli.Quiet,
+		GlobalSession: cli.Global,
+		Level:         level,
+	})
 	kongCtx.FatalIfErrorf(err)
 }